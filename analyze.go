@@ -1,324 +1,373 @@
 package golinters
 
 import (
-	"html/template"
+	"context"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"log"
-	"os"
-	"os/exec"
-	"path/filepath"
-	"strings"
-	"time"
+	"path"
 
-	"golang.org/x/tools/go/loader"
-
-	"github.com/skratchdot/open-golang/open"
-
-	"github.com/thomasheller/golinters/gometalinter"
+	"github.com/thomasheller/golinters/fetch"
 	"github.com/thomasheller/golinters/repo"
 )
 
-var (
-	linters          []linter
-	gometalinterDefs []string
-	metalintPkgs     []string
-)
-
-type result struct {
-	Name         string
-	Repo         *repo.Repository
-	GoParser     bool
-	GoLoader     bool
-	GoSSA        bool
-	Gometalinter bool
-	Metalint     bool
-	Checker      bool
-	Flag         bool
-	GoArg        bool
-	GoFlags      bool
-	Kingpin      bool
-	Pflag        bool
-	Sflags       bool
-	Notes        string
+// Options configures an analysis.
+type Options struct {
+	// Registry is the linter registry file. If empty, the built-in
+	// list of linters is used.
+	Registry string
+	// Add are linters to process in addition to those in the
+	// registry. A linter that is already defined with the same
+	// path is ignored; one with a different path is an error.
+	Add []Linter
+	// Filter selects the linters to process.
+	Filter Filter
+	// CacheDir is where linters are fetched to. If empty,
+	// fetch.DefaultDir is used.
+	CacheDir string
+	// GitHub holds optional GitHub API credentials.
+	GitHub repo.GitHubAuth
+	// Detectors are the capabilities to detect. If nil, all
+	// registered detectors are used.
+	Detectors []Detector
+	// Logger receives progress messages. If nil, nothing is
+	// logged.
+	Logger *log.Logger
+	// Progress, if not nil, is called before each linter is
+	// fetched (stage "fetch") and analyzed (stage "analyze"), with
+	// the number of linters done so far in that stage.
+	Progress func(stage, linter string, done, total int)
 }
 
-func Analyze(ghUser *string, ghToken *string, out *string) {
-	linters = list()
-
-	a := &repo.GitHubAuth{*ghUser, *ghToken}
-
-	log.Println("Fetching missing linters, if required...")
+// linters returns the linters in the registry and Add that are
+// selected by Filter.
+func (opts Options) linters() ([]linter, error) {
+	if err := opts.Filter.Validate(); err != nil {
+		return nil, err
+	}
 
-	for _, linter := range linters {
-		log.Println(linter.name)
+	linters, err := loadLinters(opts.Registry)
+	if err != nil {
+		return nil, err
+	}
 
-		if err := install(linter.path); err != nil {
-			log.Printf("Error installing %s: %v\n", linter.name, err)
-		}
+	paths := make(map[string]string)
+	for _, l := range linters {
+		paths[l.name] = l.path
 	}
 
-	if err := install("github.com/alecthomas/gometalinter"); err != nil {
-		log.Printf("Error installing gometalinter: %v\n", err)
+	for _, a := range opts.Add {
+		l := a.linter()
+		if path, ok := paths[l.name]; ok {
+			if path != l.path {
+				return nil, fmt.Errorf("linter %q is already defined with path %s", l.name, path)
+			}
+			continue
+		}
+		paths[l.name] = l.path
+		linters = append(linters, l)
 	}
 
-	var err error
+	return opts.Filter.linters(linters), nil
+}
 
-	g := &gometalinter.GometalinterSource{}
-	gometalinterDefs, err = g.GetLinterDefinitions()
-	if err != nil {
-		log.Fatalf("Error finding gometalinter's linter definitions: %v")
+// logger returns Logger, or a logger that discards everything.
+func (opts Options) logger() *log.Logger {
+	if opts.Logger == nil {
+		return log.New(ioutil.Discard, "", 0)
 	}
+	return opts.Logger
+}
 
-	metalintPkgs, err = imports("github.com/mvdan/lint/cmd/metalint")
-	if err != nil {
-		log.Fatalf("Couldn't find imports of metalint: %v")
+// progress returns Progress, or a function that does nothing.
+func (opts Options) progress() func(stage, linter string, done, total int) {
+	if opts.Progress == nil {
+		return func(string, string, int, int) {}
 	}
+	return opts.Progress
+}
 
-	var results []result
+// Result is the analysis of a single linter.
+type Result struct {
+	Name string `json:"name"`
+	// Cmd is the command line that runs the linter.
+	Cmd string `json:"cmd"`
+	// Path is the import path of the linter's main package.
+	Path string `json:"path"`
+	// Tags categorize the linter, see Filter.
+	Tags []string         `json:"tags,omitempty"`
+	Repo *repo.Repository `json:"repo,omitempty"`
+	// RepoError tells why Repo is missing.
+	RepoError string `json:"repoError,omitempty"`
+	// Version is the module version that was analyzed.
+	Version string `json:"version,omitempty"`
+	// Revision is the VCS revision of Version, see
+	// fetch.Module.Revision.
+	Revision string `json:"revision,omitempty"`
+	// Imports are all packages imported by the linter or its
+	// dependencies, see Program.Imports.
+	Imports map[string]Usage `json:"imports,omitempty"`
+	// Columns holds the outcome of each detector, in the order
+	// the detectors were given.
+	Columns []Detection `json:"columns"`
+	Notes   string      `json:"notes,omitempty"`
+	// Errors tells why the linter couldn't be analyzed (fully).
+	Errors []string `json:"errors,omitempty"`
+}
 
-	for _, linter := range linters {
-		r, err := details(linter, a)
-		if err != nil {
-			log.Printf("Error analzying %s: %v\n", linter.name, err)
-			continue
+// Column returns the outcome of the named detector.
+func (r *Result) Column(name string) (Detection, bool) {
+	for _, d := range r.Columns {
+		if d.Name == name {
+			return d, true
 		}
-		results = append(results, r)
 	}
-
-	writeHTML(*out, results)
+	return Detection{}, false
 }
 
-// install downloads a package through go get.
-func install(path string) error {
-	c := exec.Command("go", "get", "-d", path)
+// fail marks all columns of the result as unknown because of err.
+func (r *Result) fail(err error) {
+	for i := range r.Columns {
+		r.Columns[i].Usage = Unknown
+		r.Columns[i].Reason = err.Error()
+	}
+	r.Errors = append(r.Errors, err.Error())
+}
 
-	c.Stdout = os.Stdout
-	c.Stderr = os.Stderr
+// analyzer holds what's needed to analyze the linters.
+type analyzer struct {
+	fetcher   *fetch.Fetcher
+	auth      *repo.GitHubAuth
+	detectors []Detector
+	log       *log.Logger
+	// prepared holds the errors of Preparer detectors, by index.
+	prepared []error
+}
 
-	if err := c.Run(); err != nil {
-		return err
+// Analyze fetches and analyzes all linters in the registry. Linters
+// that can't be fetched or analyzed are part of the results, with
+// unknown capabilities and the reason in Result.Errors. An error is
+// only returned if the registry can't be loaded or ctx is done.
+func Analyze(ctx context.Context, opts Options) ([]Result, error) {
+	f, err := Fetch(ctx, opts)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return AnalyzeFetched(ctx, opts, f)
 }
 
-// details reports a linter's metadata, requirements and capabilities
-// based on its package path, imports and GitHub API data.
-func details(l linter, a *repo.GitHubAuth) (result, error) {
-	log.Printf("Analyzing %s...", l.name)
+// AnalyzeFetched analyzes linters that were fetched before, without
+// downloading anything, except for linters in Options.Add that
+// weren't fetched yet. Options.Registry is ignored, and
+// Options.CacheDir defaults to Fetched.CacheDir. Otherwise, it works
+// like Analyze.
+func AnalyzeFetched(ctx context.Context, opts Options, f *Fetched) ([]Result, error) {
+	if err := opts.Filter.Validate(); err != nil {
+		return nil, err
+	}
 
-	var pkgs []string
-	var err error
-	if pkgs, err = imports(l.path); err != nil {
-		return result{}, err
+	cacheDir := opts.CacheDir
+	if cacheDir == "" {
+		cacheDir = f.CacheDir
 	}
 
-	var r result
+	fetched := append([]FetchedLinter{}, f.Linters...)
 
-	r.Name = l.name
-	r.Repo, err = repo.Info(l.path, a)
-	if err != nil {
-		log.Printf("%s: could not get repository info: %v", l.name, err)
+	paths := make(map[string]string)
+	for _, fl := range f.Linters {
+		paths[fl.Name] = fl.Path
 	}
-	r.Notes = l.comment
-
-	for _, pkg := range pkgs {
-		switch pkg {
-		case "go/parser":
-			r.GoParser = true
-		case "golang.org/x/tools/go/loader":
-			r.GoLoader = true
-		case "golang.org/x/tools/go/ssa":
-			r.GoSSA = true
-		case "github.com/mvdan/lint":
-			r.Checker = true
-		case "flag":
-			r.Flag = true
-		}
-		if strings.Contains(pkg, "github.com/alexflint/go-arg") {
-			r.GoArg = true
+
+	var missing []linter
+	for _, a := range opts.Add {
+		l := a.linter()
+		if path, ok := paths[l.name]; ok {
+			if path != l.path {
+				return nil, fmt.Errorf("linter %q is already defined with path %s", l.name, path)
+			}
+			continue
 		}
-		if strings.Contains(pkg, "github.com/jessevdk/go-flags") {
-			r.GoFlags = true
+		paths[l.name] = l.path
+		missing = append(missing, l)
+	}
+
+	if missing = opts.Filter.linters(missing); len(missing) > 0 {
+		fls, err := fetchLinters(ctx, &fetch.Fetcher{Dir: cacheDir}, missing, opts)
+		if err != nil {
+			return nil, err
 		}
-		if strings.Contains(pkg, "github.com/spf13/pflag") {
-			r.Pflag = true
+		fetched = append(fetched, fls...)
+	}
+
+	an := &analyzer{
+		fetcher:   &fetch.Fetcher{Dir: cacheDir, Offline: true},
+		auth:      &opts.GitHub,
+		detectors: opts.Detectors,
+		log:       opts.logger(),
+	}
+
+	if an.detectors == nil {
+		an.detectors = Detectors()
+	}
+
+	progress := opts.progress()
+
+	an.prepare(ctx)
+
+	var results []Result
+
+	var linters []FetchedLinter
+	for _, fl := range fetched {
+		if opts.Filter.Match(fl.Name, fl.Tags) {
+			linters = append(linters, fl)
 		}
-		if strings.Contains(pkg, "github.com/octago/sflags/gen/gflag") {
-			r.Sflags = true
+	}
+
+	for i, fl := range linters {
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
-		if strings.Contains(pkg, "gopkg.in/alecthomas/kingpin") {
-			r.Kingpin = true
+
+		progress("analyze", fl.Name, i, len(linters))
+
+		var r Result
+		switch {
+		case fl.Error != "":
+			r = an.details(ctx, fl.linter(), nil, fmt.Errorf("fetch failed: %s", fl.Error))
+		case fl.Module == nil:
+			r = an.details(ctx, fl.linter(), nil, errors.New("module not found in fetched linters"))
+		default:
+			r = an.details(ctx, fl.linter(), fl.Module, nil)
 		}
+		results = append(results, r)
 	}
 
-	for _, def := range gometalinterDefs {
-		if strings.HasPrefix(def, l.cmd) {
-			r.Gometalinter = true
-			break
-		}
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
-	for _, metalintPkg := range metalintPkgs {
-		if l.path == metalintPkg {
-			r.Metalint = true
-			break
+	return results, nil
+}
+
+// prepare runs all Preparer detectors. Detectors that fail to prepare
+// will report unknown results for all linters.
+func (an *analyzer) prepare(ctx context.Context) {
+	an.prepared = make([]error, len(an.detectors))
+
+	for i, d := range an.detectors {
+		if p, ok := d.(Preparer); ok {
+			if err := p.Prepare(ctx, an.fetcher); err != nil {
+				an.log.Printf("Error preparing %s detector: %v", d.Name(), err)
+				an.prepared[i] = err
+			}
 		}
 	}
-
-	return r, nil
 }
 
-// imports returns all imports for the given package.
-func imports(path string) ([]string, error) {
-	args := []string{path}
+// details reports a linter's metadata, requirements and capabilities
+// based on its package path, imports and GitHub API data. The linter
+// is analyzed from the module m it was fetched into. If the linter
+// couldn't be fetched, fetchErr tells why, and all capabilities are
+// unknown.
+func (an *analyzer) details(ctx context.Context, l linter, m *fetch.Module, fetchErr error) Result {
+	an.log.Printf("Analyzing %s...", l.name)
+
+	r := Result{
+		Name:    l.name,
+		Cmd:     l.cmd,
+		Path:    l.path,
+		Tags:    l.tags,
+		Notes:   l.comment,
+		Columns: make([]Detection, len(an.detectors)),
+	}
 
-	var conf loader.Config
-	if _, err := conf.FromArgs(args, false); err != nil {
-		return nil, err
+	for i, d := range an.detectors {
+		r.Columns[i] = Detection{Name: d.Name(), Group: d.Group()}
 	}
 
-	var lprog *loader.Program
 	var err error
-	if lprog, err = conf.Load(); err != nil {
-		return nil, err
+	r.Repo, err = repo.DefaultResolver.Info(ctx, l.path, an.auth)
+	if err != nil {
+		an.log.Printf("%s: could not get repository info: %v", l.name, err)
+		r.RepoError = err.Error()
 	}
 
-	var imports []string
-	for p := range lprog.AllPackages {
-		imports = append(imports, p.Path())
+	if fetchErr != nil {
+		r.fail(fetchErr)
+		return r
 	}
 
-	return imports, nil
-}
+	r.Version = m.Version
+	r.Revision = m.Revision()
+
+	p, err := loadProgram(ctx, an.fetcher, l, m)
+	if err != nil {
+		an.log.Printf("Error analyzing %s: %v\n", l.name, err)
+		r.fail(fmt.Errorf("load failed: %v", err))
+		return r
+	}
 
-// writeHTML generates a HTML report and writes it to a file. If no
-// filename is given, a temporary file is chosen and the report opens
-// in the default browser.
-func writeHTML(file string, results []result) error {
-	browser := file == ""
+	r.Imports = p.Imports
 
-	tmpl := template.Must(template.New("html").Parse(htmlTemplate))
+	for i, d := range an.detectors {
+		col := &r.Columns[i]
 
-	if browser {
-		dir, err := ioutil.TempDir("", "golinters")
-		if err != nil {
-			return err
+		if err := an.prepared[i]; err != nil {
+			col.Usage = Unknown
+			col.Reason = fmt.Sprintf("%s detector unavailable: %v", d.Name(), err)
+			continue
 		}
 
-		file = filepath.Join(dir, "golinters.html")
-	}
-
-	out, err := os.Create(file)
-	if err != nil {
-		return err
+		det := d.Detect(p)
+		if r.Repo != nil {
+			for j, e := range det.Evidence {
+				if e.Module == m.Path && e.File != "" {
+					det.Evidence[j].URL = r.Repo.BlobURL(m.Revision(), path.Join(m.Subdir(), e.File), e.Line)
+				}
+			}
+		}
+		col.Usage, col.Evidence, col.Reason = det.Usage, det.Evidence, det.Reason
 	}
 
-	defer out.Close()
+	return r
+}
 
-	data := TemplateData{
-		Timestamp: time.Now().Format(time.RFC1123),
-		Results:   results,
+// loadProgram loads a linter from the fetched module m. It also
+// determines all packages imported by the linter or any of its
+// dependencies, and how directly they are used: a package imported
+// by the main package itself counts more than one that is only
+// imported by a package of the same module, which in turn counts
+// more than one only imported by third-party dependencies.
+func loadProgram(ctx context.Context, f *fetch.Fetcher, l linter, m *fetch.Module) (*Program, error) {
+	conf, err := f.LoadConfig(m)
+	if err != nil {
+		return nil, err
 	}
 
-	err = tmpl.Execute(out, data)
+	prog, err := conf.Load(ctx, l.path)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if browser {
-		open.Run("file://" + out.Name())
+	p := &Program{
+		Name:     l.name,
+		Cmd:      l.cmd,
+		Path:     l.path,
+		Module:   m,
+		Packages: prog,
+		Imports:  make(map[string]Usage),
 	}
 
-	return nil
-}
+	for _, pkg := range prog.AllPackages() {
+		u := p.usageOf(pkg)
+		for imp := range pkg.Imports {
+			v := p.Imports[imp]
+			v.add(u)
+			p.Imports[imp] = v
+		}
+	}
 
-type TemplateData struct {
-	Timestamp string
-	Results   []result
+	return p, nil
 }
-
-const htmlTemplate = `<!DOCTYPE html>
-<html>
-	<head>
-		<meta http-equiv="Content-Type" content="text/html; charset=utf-8">
-		<style>
-			html, body {
-				font-family: Arial, sans-serif;
-			}
-			tt {
-				font-family: Menlo, monospace;
-			}
-			table, th, td {
-				border: 1px solid #000;
-				border-collapse: collapse;
-			}
-			th, td {
-				padding: .33em;
-			}
-			td.t, td.f {
-				text-align: center;
-			}
-			td.t {
-				background-color: #5bd64a;
-			}
-			td.f {
-				background-color: #d64a4a;
-			}
-			td.notes, .timestamp {
-				font-size: small;
-			}
-		</style>
-	</head>
-	<body>
-		<table>
-			<thead>
-				<tr>
-					<th colspan="3">General info</th>
-					<th colspan="3">Input</th>
-					<th colspan="3">Metalinter support</th>
-					<th colspan="6">Options</th>
-					<th rowspan="2">Notes</th>
-				</tr>
-				<tr>
-					<th>Name</th>
-					<th>Maintainer</th>
-					<th>Repository URL</th>
-					<th><tt>go/parser</tt></th>
-					<th><tt>go/loader</tt></th>
-					<th><tt>go/ssa</tt></th>
-					<th><tt>gometalinter</tt></th>
-					<th><tt>metalint</tt></th>
-					<th><tt>Checker</tt></th>
-					<th><tt>flag</tt></th>
-					<th><tt>go-arg</tt></th>
-					<th><tt>go-flags</tt></th>
-					<th><tt>kingpin</tt></th>
-					<th><tt>pflag</tt></th>
-					<th><tt>sflags</tt></th>
-				</tr>
-			</thead>
-			<tbody>
-				{{ range .Results }}<tr>
-					<td>{{ .Name }}</td>
-					<td>{{ if .Repo }}{{ .Repo.Maintainer }}{{ end }}</td>
-					<td>{{ if .Repo }}<a href="{{ .Repo.URL }}">{{ .Repo.URL }}</a>{{ end }}</td>
-					{{ if .GoParser }}<td class="t">Y</td>{{ else }}<td class="f">N</td>{{ end }}
-					{{ if .GoLoader }}<td class="t">Y</td>{{ else }}<td class="f">N</td>{{ end }}
-					{{ if .GoSSA }}<td class="t">Y</td>{{ else }}<td class="f">N</td>{{ end }}
-					{{ if .Gometalinter }}<td class="t">Y</td>{{ else }}<td class="f">N</td>{{ end }}
-					{{ if .Metalint }}<td class="t">Y</td>{{ else }}<td class="f">N</td>{{ end }}
-					{{ if .Checker }}<td class="t">Y</td>{{ else }}<td class="f">N</td>{{ end }}
-					{{ if .Flag }}<td class="t">Y</td>{{ else }}<td class="f">N</td>{{ end }}
-					{{ if .GoArg }}<td class="t">Y</td>{{ else }}<td class="f">N</td>{{ end }}
-					{{ if .GoFlags }}<td class="t">Y</td>{{ else }}<td class="f">N</td>{{ end }}
-					{{ if .Kingpin }}<td class="t">Y</td>{{ else }}<td class="f">N</td>{{ end }}
-					{{ if .Pflag }}<td class="t">Y</td>{{ else }}<td class="f">N</td>{{ end }}
-					{{ if .Sflags }}<td class="t">Y</td>{{ else }}<td class="f">N</td>{{ end }}
-					<td class="notes">{{ .Notes }}</td>
-				</tr>{{ end }}
-			</tbody>
-		</table>
-		<p class="timestamp">{{ .Timestamp }}</p>
-	</body>
-</html>`
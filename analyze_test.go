@@ -0,0 +1,96 @@
+package golinters
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseLinter(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Linter
+		err  bool
+	}{
+		{in: "github.com/kisielk/errcheck", want: Linter{Name: "errcheck", Path: "github.com/kisielk/errcheck"}},
+		{in: "github.com/foo/bar/cmd/bar", want: Linter{Name: "bar", Path: "github.com/foo/bar/cmd/bar"}},
+		{in: "foo", want: Linter{Name: "foo", Path: "foo"}},
+		{in: "check=github.com/kisielk/errcheck", want: Linter{Name: "check", Path: "github.com/kisielk/errcheck"}},
+		{in: " check = github.com/kisielk/errcheck ", want: Linter{Name: "check", Path: "github.com/kisielk/errcheck"}},
+		{in: "=github.com/kisielk/errcheck", want: Linter{Name: "errcheck", Path: "github.com/kisielk/errcheck"}},
+
+		{in: "", err: true},
+		{in: "check=", err: true},
+		{in: "check=github.com/kisielk/err check", err: true},
+		{in: "/usr/bin/errcheck", err: true},
+		{in: "github.com/kisielk/errcheck/", err: true},
+	}
+
+	for _, test := range tests {
+		got, err := ParseLinter(test.in)
+		if (err != nil) != test.err {
+			t.Errorf("%q: got error %v, want error: %t", test.in, err, test.err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%q: got %+v, want %+v", test.in, got, test.want)
+		}
+	}
+}
+
+func TestOptionsLinters(t *testing.T) {
+	registry := filepath.Join(t.TempDir(), "linters.yaml")
+	data := `
+linters:
+  - name: errcheck
+    path: github.com/kisielk/errcheck
+  - name: vet
+    cmd: go vet
+    path: github.com/golang/go/src/cmd/vet
+`
+	if err := ioutil.WriteFile(registry, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		add  []string
+		want []string
+		err  bool
+	}{
+		{name: "none", want: []string{"errcheck", "vet"}},
+		{name: "bare path", add: []string{"github.com/foo/bar/cmd/bar"}, want: []string{"errcheck", "vet", "bar"}},
+		{name: "name=path", add: []string{"baz=github.com/foo/bar/cmd/bar"}, want: []string{"errcheck", "vet", "baz"}},
+		{name: "same as registry, name=path", add: []string{"errcheck=github.com/kisielk/errcheck"}, want: []string{"errcheck", "vet"}},
+		{name: "same as registry, bare path", add: []string{"github.com/kisielk/errcheck"}, want: []string{"errcheck", "vet"}},
+		{name: "given twice", add: []string{"github.com/foo/bar/cmd/bar", "bar=github.com/foo/bar/cmd/bar"}, want: []string{"errcheck", "vet", "bar"}},
+
+		{name: "other path than registry", add: []string{"errcheck=github.com/foo/errcheck"}, err: true},
+		{name: "other path than -add", add: []string{"github.com/foo/bar/cmd/bar", "github.com/foo/baz/cmd/bar"}, err: true},
+	}
+
+	for _, test := range tests {
+		opts := Options{Registry: registry}
+		for _, s := range test.add {
+			l, err := ParseLinter(s)
+			if err != nil {
+				t.Fatalf("%s: %v", test.name, err)
+			}
+			opts.Add = append(opts.Add, l)
+		}
+
+		linters, err := opts.linters()
+		if (err != nil) != test.err {
+			t.Errorf("%s: got error %v, want error: %t", test.name, err, test.err)
+			continue
+		}
+		var got []string
+		for _, l := range linters {
+			got = append(got, l.name)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
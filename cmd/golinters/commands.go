@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/thomasheller/golinters"
+)
+
+// Default names of the intermediate files in the cache directory.
+const (
+	fetchedFile = "fetched.json"
+	resultsFile = "results.json"
+)
+
+// fetchCmd implements "golinters fetch".
+func fetchCmd(args []string) {
+	fs := flag.NewFlagSet("fetch", flag.ExitOnError)
+	af := addFetchFlags(fs)
+	ff := addFilterFlags(fs)
+	out := fs.String("write", "", "file to list the fetched linters in (default: "+fetchedFile+" in the cache directory)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: golinters fetch [flags]\n\nDownloads the latest versions of all linters into the cache.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	file, err := cacheFile(*out, *af.cache, fetchedFile)
+	if err != nil {
+		log.Fatalf("Error writing fetched linters: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	opts := af.options()
+	opts.Filter = ff.filter()
+
+	f, err := golinters.Fetch(ctx, opts)
+	if err != nil {
+		log.Fatalf("Error fetching linters:\n%v", err)
+	}
+
+	err = writeFile(file, func(w io.Writer) error {
+		return golinters.WriteFetched(w, f)
+	})
+	if err != nil {
+		log.Fatalf("Error writing fetched linters: %v", err)
+	}
+}
+
+// analyzeCmd implements "golinters analyze".
+func analyzeCmd(args []string) {
+	fs := flag.NewFlagSet("analyze", flag.ExitOnError)
+	af := addFetchedFlags(fs)
+	ff := addFilterFlags(fs)
+	in := fs.String("fetched", "", "file written by golinters fetch (default: "+fetchedFile+" in the cache directory)")
+	out := fs.String("write", "", "file to write the results to (default: "+resultsFile+" in the cache directory)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: golinters analyze [flags]\n\nAnalyzes the linters downloaded by golinters fetch. Nothing is\ndownloaded, except for linters given by -add, but repository metadata\nis looked up: go-import meta tags of vanity import paths and the APIs\nof GitHub, GitLab, Bitbucket and Gitea.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	inFile, err := cacheFile(*in, *af.cache, fetchedFile)
+	if err != nil {
+		log.Fatalf("Error reading fetched linters: %v", err)
+	}
+
+	outFile, err := cacheFile(*out, *af.cache, resultsFile)
+	if err != nil {
+		log.Fatalf("Error writing results: %v", err)
+	}
+
+	f, err := readFetched(inFile)
+	if err != nil {
+		log.Fatalf("Error reading fetched linters (run golinters fetch first): %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	opts := af.options()
+	opts.Filter = ff.filter()
+
+	results, err := golinters.AnalyzeFetched(ctx, opts, f)
+	if err != nil {
+		log.Fatalf("Error analyzing linters:\n%v", err)
+	}
+
+	report := golinters.NewReport(results)
+
+	af.record(report, opts.Filter)
+
+	if err := writeFile(outFile, report.WriteJSON); err != nil {
+		log.Fatalf("Error writing results: %v", err)
+	}
+}
+
+// reportCmd implements "golinters report".
+func reportCmd(args []string) {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	rf := addReportFlags(fs)
+	ff := addFilterFlags(fs)
+	cache := fs.String("cache", "", "cache directory of the default results file (default: golinters in the user cache directory)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: golinters report [flags] [results.json]\n\nWrites a report of results written by golinters analyze (default:\n"+resultsFile+" in the cache directory) or with -format json.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() > 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	rf.check()
+	filter := ff.filter()
+
+	file, err := cacheFile(fs.Arg(0), *cache, resultsFile)
+	if err != nil {
+		log.Fatalf("Error reading results: %v", err)
+	}
+
+	report, err := readReport(file)
+	if err != nil {
+		log.Fatalf("Error reading results (run golinters analyze first): %v", err)
+	}
+
+	report.Results = filter.Results(report.Results)
+
+	if err := rf.write(report); err != nil {
+		log.Fatalf("Error writing report: %v", err)
+	}
+}
+
+// removeCmd implements "golinters remove".
+func removeCmd(args []string) {
+	fs := flag.NewFlagSet("remove", flag.ExitOnError)
+	af := addFetchFlags(fs)
+	dryRun := fs.Bool("dry-run", false, "only list what would be removed")
+	ff := addFilterFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: golinters remove [flags]\n\nDeletes the directories golinters fetched the linters to. Directories\nthat golinters didn't create, that other packages were fetched to or\nthat have uncommitted changes are kept.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	opts := af.options()
+	opts.Filter = ff.filter()
+
+	removals, err := golinters.Remove(ctx, opts, *dryRun)
+	if err == nil && len(removals) == 0 {
+		fmt.Println("nothing to remove")
+	}
+	for _, r := range removals {
+		switch {
+		case r.Dir == "":
+			fmt.Printf("skipping %s: %s\n", strings.Join(r.Packages, ", "), r.Reason)
+		case *dryRun && r.Reason == "":
+			fmt.Printf("would remove %s\n", r.Dir)
+		default:
+			fmt.Println(r)
+		}
+	}
+	if err != nil {
+		log.Fatalf("Error removing linters:\n%v", err)
+	}
+}
+
+// readFetched reads a file written by golinters fetch.
+func readFetched(file string) (*golinters.Fetched, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return golinters.ReadFetched(f)
+}
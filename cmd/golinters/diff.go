@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/thomasheller/golinters"
+)
+
+// diff implements "golinters diff old.json new.json".
+func diff(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	format := fs.String("format", "text", "output format: text, json or html")
+	out := fs.String("write", "", "write output to file instead of printing it")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: golinters diff [flags] old.json new.json\n\nCompares two reports written with -format json.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	older, err := readReport(fs.Arg(0))
+	if err != nil {
+		log.Fatalf("Error reading %s: %v", fs.Arg(0), err)
+	}
+
+	newer, err := readReport(fs.Arg(1))
+	if err != nil {
+		log.Fatalf("Error reading %s: %v", fs.Arg(1), err)
+	}
+
+	d := golinters.DiffReports(older, newer)
+
+	var write func(io.Writer) error
+	switch *format {
+	case "text":
+		write = d.WriteText
+	case "json":
+		write = d.WriteJSON
+	case "html":
+		write = d.WriteHTML
+	default:
+		log.Fatalf("Unknown output format %q", *format)
+	}
+
+	w := os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatalf("Error writing diff: %v", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := write(w); err != nil {
+		log.Fatalf("Error writing diff: %v", err)
+	}
+}
+
+// readReport reads a JSON report from a file.
+func readReport(file string) (*golinters.Report, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return golinters.ReadJSON(f)
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/thomasheller/golinters"
+	"github.com/thomasheller/golinters/fetch"
+	"github.com/thomasheller/golinters/repo"
+)
+
+// analysisFlags are the flags of commands that fetch or analyze
+// linters. Flags that a command doesn't have are nil.
+type analysisFlags struct {
+	registry *string
+	cache    *string
+	ghUser   *string
+	ghToken  *string
+	history  *string
+	add      listFlag
+}
+
+// addAnalysisFlags adds the flags of commands that both fetch and
+// analyze the linters.
+func addAnalysisFlags(fs *flag.FlagSet) *analysisFlags {
+	f := addFetchFlags(fs)
+	f.addAnalyzeFlags(fs)
+	return f
+}
+
+// addFetchFlags adds the flags that tell which linters to fetch and
+// where to: -linters, -cache and -add.
+func addFetchFlags(fs *flag.FlagSet) *analysisFlags {
+	f := addCacheFlags(fs)
+	f.registry = fs.String("linters", "", "read linter registry from YAML, TOML or JSON file instead of using the built-in list")
+	return f
+}
+
+// addFetchedFlags adds the flags of commands that analyze linters
+// fetched before. The registry was read when fetching, so there's no
+// -linters.
+func addFetchedFlags(fs *flag.FlagSet) *analysisFlags {
+	f := addCacheFlags(fs)
+	f.addAnalyzeFlags(fs)
+	return f
+}
+
+// addCacheFlags adds -cache and -add.
+func addCacheFlags(fs *flag.FlagSet) *analysisFlags {
+	f := &analysisFlags{
+		cache: fs.String("cache", "", "directory to download linters to (default: golinters in the user cache directory)"),
+	}
+	fs.Var(&f.add, "add", "also process the linter given as name=path, or just the import path (may be repeated)")
+	return f
+}
+
+// addAnalyzeFlags adds the GitHub credentials and -history.
+func (f *analysisFlags) addAnalyzeFlags(fs *flag.FlagSet) {
+	f.ghUser = fs.String("ghuser", "", "GitHub username (for API use)")
+	f.ghToken = fs.String("ghtoken", "", "GitHub token (for API use)")
+	f.history = fs.String("history", "", "history database to record runs in (default: history.db in the cache directory, \"none\" to disable)")
+}
+
+// options returns the analysis options given by the flags. It exits
+// if an added linter is malformed.
+func (f *analysisFlags) options() golinters.Options {
+	opts := golinters.Options{
+		Registry: value(f.registry),
+		CacheDir: *f.cache,
+		GitHub:   repo.GitHubAuth{Username: value(f.ghUser), Token: value(f.ghToken)},
+		Logger:   log.New(os.Stderr, "", log.LstdFlags),
+	}
+
+	for _, s := range f.add {
+		l, err := golinters.ParseLinter(s)
+		if err != nil {
+			log.Fatalf("Invalid -add: %v", err)
+		}
+		opts.Add = append(opts.Add, l)
+	}
+
+	return opts
+}
+
+// fetchArgs returns the flags added by addFetchFlags as command line
+// arguments.
+func (f *analysisFlags) fetchArgs() []string {
+	args := []string{"-linters", value(f.registry), "-cache", *f.cache}
+	for _, a := range f.add {
+		args = append(args, "-add", a)
+	}
+	return args
+}
+
+// record adds a run to the history database, unless disabled or
+// limited to some linters by filter: the linters left out would show
+// up as removed in the timelines. Errors are logged.
+func (f *analysisFlags) record(report *golinters.Report, filter golinters.Filter) {
+	if f.history == nil || *f.history == "none" {
+		return
+	}
+	if len(filter.Only) > 0 || len(filter.Exclude) > 0 {
+		log.Printf("Not recording run in history, since -only or -exclude left out linters")
+		return
+	}
+
+	if err := record(*f.history, *f.cache, report); err != nil {
+		log.Printf("Error recording run in history: %v", err)
+	}
+}
+
+// value returns the value of a flag that may not have been added.
+func value(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// listFlag is a flag that takes comma-separated values and may be
+// given multiple times.
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *listFlag) Set(s string) error {
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
+
+// filterFlags are the flags that select linters.
+type filterFlags struct {
+	only, exclude listFlag
+}
+
+func addFilterFlags(fs *flag.FlagSet) *filterFlags {
+	f := &filterFlags{}
+	fs.Var(&f.only, "only", "only process linters whose name or tag matches any of these comma-separated glob patterns")
+	fs.Var(&f.exclude, "exclude", "skip linters whose name or tag matches any of these comma-separated glob patterns")
+	return f
+}
+
+// args returns the flags as command line arguments.
+func (f *filterFlags) args() []string {
+	var args []string
+	for _, p := range f.only {
+		args = append(args, "-only", p)
+	}
+	for _, p := range f.exclude {
+		args = append(args, "-exclude", p)
+	}
+	return args
+}
+
+// filter returns the filter given by the flags. It exits if a pattern
+// is malformed.
+func (f *filterFlags) filter() golinters.Filter {
+	filter := golinters.Filter{Only: f.only, Exclude: f.exclude}
+	if err := filter.Validate(); err != nil {
+		log.Fatalf("Invalid -only or -exclude: %v", err)
+	}
+	return filter
+}
+
+// cacheFile returns file if given, otherwise the file with the given
+// name in the cache directory.
+func cacheFile(file, cache, name string) (string, error) {
+	if file != "" {
+		return file, nil
+	}
+
+	if cache == "" {
+		dir, err := fetch.DefaultDir()
+		if err != nil {
+			return "", err
+		}
+		cache = dir
+	}
+
+	return filepath.Join(cache, name), nil
+}
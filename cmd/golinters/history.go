@@ -0,0 +1,100 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/thomasheller/golinters"
+	"github.com/thomasheller/golinters/history"
+)
+
+// runHistory implements "golinters history [linter...]".
+func runHistory(args []string) {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	db := fs.String("history", "", "history database (default: history.db in the cache directory)")
+	cache := fs.String("cache", "", "cache directory of the recorded runs (default: golinters in the user cache directory)")
+	format := fs.String("format", "text", "output format: text, json or html")
+	out := fs.String("write", "", "write output to file instead of printing it")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: golinters history [flags] [linter...]\n\nShows how linters changed across recorded runs.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	var write func(io.Writer, []history.Timeline) error
+	switch *format {
+	case "text":
+		write = history.WriteText
+	case "json":
+		write = history.WriteJSON
+	case "html":
+		write = history.WriteHTML
+	default:
+		log.Fatalf("Unknown output format %q", *format)
+	}
+
+	file, err := cacheFile(*db, *cache, "history.db")
+	if err != nil {
+		log.Fatalf("Error opening history: %v", err)
+	}
+
+	h, err := history.Open(file)
+	if err != nil {
+		log.Fatalf("Error opening history: %v", err)
+	}
+	defer h.Close()
+
+	runs, err := h.Runs()
+	if err != nil {
+		log.Fatalf("Error reading history: %v", err)
+	}
+
+	timelines := history.Timelines(runs)
+
+	if fs.NArg() > 0 {
+		want := make(map[string]bool)
+		for _, name := range fs.Args() {
+			want[name] = true
+		}
+		var filtered []history.Timeline
+		for _, t := range timelines {
+			if want[t.Linter] {
+				filtered = append(filtered, t)
+			}
+		}
+		timelines = filtered
+	}
+
+	w := os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatalf("Error writing history: %v", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := write(w, timelines); err != nil {
+		log.Fatalf("Error writing history: %v", err)
+	}
+}
+
+// record adds a run to the history database.
+func record(file, cache string, report *golinters.Report) error {
+	file, err := cacheFile(file, cache, "history.db")
+	if err != nil {
+		return err
+	}
+
+	h, err := history.Open(file)
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+
+	return h.Add(report)
+}
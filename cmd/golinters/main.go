@@ -1,21 +1,93 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
 
 	"github.com/thomasheller/golinters"
 )
 
+// commands are the subcommands, by name.
+var commands = map[string]func(args []string){
+	"fetch":   fetchCmd,
+	"analyze": analyzeCmd,
+	"report":  reportCmd,
+	"remove":  removeCmd,
+	"diff":    diff,
+	"history": runHistory,
+	"serve":   serve,
+}
+
+const usage = `usage: golinters [flags]
+       golinters <command> [flags] [arguments]
+
+Without a command, golinters fetches and analyzes all linters and
+writes a report in one go. The commands run a single step each:
+
+	fetch    download the linters into the cache
+	analyze  analyze the fetched linters, without downloading anything
+	report   write a report of the analysis, in any format
+	remove   delete the linters
+	diff     compare two reports
+	history  show how linters changed across runs
+	serve    serve the report over HTTP
+
+Run "golinters <command> -h" for the flags of a command.
+
+Flags:
+`
+
 func main() {
-	out := flag.String("write", "", "write HTML output to file instead of opening a browser")
-	ghUser := flag.String("ghuser", "", "GitHub username (for API use)")
-	ghToken := flag.String("ghtoken", "", "GitHub token (for API use)")
-	remove := flag.Bool("remove", false, "delete all linters in GOPATH/src (be careful)")
-	flag.Parse()
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			cmd(os.Args[2:])
+			return
+		}
+	}
+
+	run(os.Args[1:])
+}
+
+// run fetches and analyzes the linters and writes a report.
+func run(args []string) {
+	fs := flag.NewFlagSet("golinters", flag.ExitOnError)
+	af := addAnalysisFlags(fs)
+	rf := addReportFlags(fs)
+	ff := addFilterFlags(fs)
+	remove := fs.Bool("remove", false, "deprecated: use golinters remove")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
 
 	if *remove {
-		golinters.RemoveAllRepos()
-	} else {
-		golinters.Analyze(ghUser, ghToken, out)
+		removeCmd(append(af.fetchArgs(), ff.args()...))
+		return
+	}
+
+	rf.check()
+
+	opts := af.options()
+	opts.Filter = ff.filter()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	results, err := golinters.Analyze(ctx, opts)
+	if err != nil {
+		log.Fatalf("Error analyzing linters:\n%v", err)
+	}
+
+	report := golinters.NewReport(results)
+
+	af.record(report, opts.Filter)
+
+	if err := rf.write(report); err != nil {
+		log.Fatalf("Error writing report: %v", err)
 	}
 }
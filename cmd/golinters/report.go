@@ -0,0 +1,155 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/skratchdot/open-golang/open"
+
+	"github.com/thomasheller/golinters"
+)
+
+// reportFlags are the flags of commands that write a report.
+type reportFlags struct {
+	out      *string
+	format   *string
+	tmplFile *string
+	partials *string
+
+	site func(report *golinters.Report, dir string) error
+}
+
+func addReportFlags(fs *flag.FlagSet) *reportFlags {
+	return &reportFlags{
+		out:      fs.String("write", "", "write output to file (directory for site) instead of opening a browser (HTML) or printing it"),
+		format:   fs.String("format", "html", "output format: html, json, markdown, csv, xlsx or site"),
+		tmplFile: fs.String("template", "", "render HTML with a custom html/template file"),
+		partials: fs.String("partials", "", "directory with partial templates (*.html, *.tmpl) for -template"),
+		site:     (*golinters.Report).WriteSite,
+	}
+}
+
+// check validates the flags and loads the template, if any. It
+// exits on errors, so it should be called before any work is done.
+func (f *reportFlags) check() {
+	if _, ok := writers[*f.format]; !ok && *f.format != "site" {
+		log.Fatalf("Unknown output format %q", *f.format)
+	}
+
+	if *f.format == "xlsx" && *f.out == "" {
+		log.Fatalf("XLSX output needs a file, use -write")
+	}
+
+	if *f.format == "site" && *f.out == "" {
+		log.Fatalf("Site output needs a directory, use -write")
+	}
+
+	if *f.tmplFile != "" {
+		tmpl, err := golinters.ParseHTMLTemplate(*f.tmplFile, *f.partials)
+		if err != nil {
+			log.Fatalf("Error loading template: %v", err)
+		}
+		writers["html"] = func(w io.Writer, report *golinters.Report) error {
+			return report.WriteHTMLTemplate(w, tmpl)
+		}
+		f.site = func(report *golinters.Report, dir string) error {
+			return report.WriteSiteTemplate(dir, tmpl)
+		}
+	}
+}
+
+// write writes the report as given by the flags. HTML reports and
+// sites are labeled with the report's timestamp, i.e. when the
+// analysis ran, not when they are written.
+func (f *reportFlags) write(report *golinters.Report) error {
+	if *f.format == "site" {
+		return f.site(report, *f.out)
+	}
+
+	return writeReport(*f.format, *f.out, report)
+}
+
+var writers = map[string]func(io.Writer, *golinters.Report) error{
+	"html":     func(w io.Writer, r *golinters.Report) error { return r.WriteHTML(w) },
+	"json":     func(w io.Writer, r *golinters.Report) error { return r.WriteJSON(w) },
+	"markdown": results(golinters.WriteMarkdown),
+	"csv":      results(golinters.WriteCSV),
+	"xlsx":     results(golinters.WriteXLSX),
+}
+
+// results adapts a function that writes results to write reports.
+func results(write func(io.Writer, []golinters.Result) error) func(io.Writer, *golinters.Report) error {
+	return func(w io.Writer, r *golinters.Report) error {
+		return write(w, r.Results)
+	}
+}
+
+// writeReport writes the report in the given format to a file. If no
+// filename is given, HTML reports are written to a temporary file
+// and opened in the default browser; other formats are printed.
+func writeReport(format, file string, report *golinters.Report) error {
+	if format != "html" {
+		return writeFile(file, func(w io.Writer) error {
+			return writers[format](w, report)
+		})
+	}
+
+	return writeHTML(file, report)
+}
+
+// writeFile writes to a file with write, or to stdout if no filename
+// is given.
+func writeFile(file string, write func(io.Writer) error) error {
+	if file == "" {
+		return write(os.Stdout)
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// writeHTML generates a HTML report and writes it to a file. If no
+// filename is given, a temporary file is chosen and the report opens
+// in the default browser.
+func writeHTML(file string, report *golinters.Report) error {
+	browser := file == ""
+
+	if browser {
+		dir, err := ioutil.TempDir("", "golinters")
+		if err != nil {
+			return err
+		}
+
+		file = filepath.Join(dir, "golinters.html")
+	}
+
+	out, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+
+	defer out.Close()
+
+	if err := writers["html"](out, report); err != nil {
+		return err
+	}
+
+	if browser {
+		open.Run("file://" + out.Name())
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+
+	"github.com/thomasheller/golinters"
+	"github.com/thomasheller/golinters/server"
+)
+
+// serve implements "golinters serve".
+func serve(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:8080", "address to listen on")
+	results := fs.String("results", "", "serve a report written with -format json instead of analyzing the linters first")
+	af := addAnalysisFlags(fs)
+	ff := addFilterFlags(fs)
+	tmplFile := fs.String("template", "", "render the report with a custom html/template file")
+	partials := fs.String("partials", "", "directory with partial templates (*.html, *.tmpl) for -template")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: golinters serve [flags]\n\nServes the report, a page per linter and a JSON API over HTTP.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	var report *golinters.Report
+	if *results != "" {
+		var err error
+		if report, err = readReport(*results); err != nil {
+			log.Fatalf("Error reading %s: %v", *results, err)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	opts := af.options()
+	opts.Filter = ff.filter()
+
+	if report != nil {
+		report.Results = opts.Filter.Results(report.Results)
+	}
+
+	s := server.New(ctx, opts, report)
+
+	if *tmplFile != "" {
+		tmpl, err := golinters.ParseHTMLTemplate(*tmplFile, *partials)
+		if err != nil {
+			log.Fatalf("Error loading template: %v", err)
+		}
+		s.Template = tmpl
+	}
+
+	s.OnReport = func(r *golinters.Report) { af.record(r, opts.Filter) }
+
+	if report == nil {
+		s.Analyze()
+	}
+
+	srv := &http.Server{Addr: *addr, Handler: s}
+
+	go func() {
+		<-ctx.Done()
+		srv.Shutdown(context.Background())
+	}()
+
+	log.Printf("Serving report at http://%s/", *addr)
+
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		log.Fatalf("Error serving report: %v", err)
+	}
+}
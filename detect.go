@@ -0,0 +1,334 @@
+package golinters
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/thomasheller/golinters/fetch"
+	"github.com/thomasheller/golinters/gometalinter"
+	"github.com/thomasheller/golinters/load"
+)
+
+// Detector detects a capability of a linter. Each registered
+// detector is a column in the report.
+type Detector interface {
+	// Name is the column header.
+	Name() string
+	// Group is the column group in the report, e.g. "Input".
+	Group() string
+	// Detect reports whether (and how directly) a linter has the
+	// capability, and why.
+	Detect(p *Program) Detection
+}
+
+// Preparer is implemented by detectors that need to fetch or compute
+// something once before any linter is analyzed.
+type Preparer interface {
+	Prepare(ctx context.Context, f *fetch.Fetcher) error
+}
+
+// Program is a linter that was loaded for analysis.
+type Program struct {
+	// Name is the linter's name.
+	Name string
+	// Cmd is the command line that runs the linter.
+	Cmd string
+	// Path is the import path of the linter's main package.
+	Path string
+	// Module is the module the linter was fetched to.
+	Module *fetch.Module
+	// Packages are the linter's main package and all its
+	// dependencies.
+	Packages *load.Program
+	// Imports are all packages imported anywhere in the program
+	// and how directly they are used.
+	Imports map[string]Usage
+
+	fset    *token.FileSet
+	imports map[string][]*ast.ImportSpec // by file name
+}
+
+// usageOf reports how close pkg is to the linter.
+func (p *Program) usageOf(pkg *packages.Package) Usage {
+	switch {
+	case pkg.PkgPath == p.Path:
+		return UsedByMain
+	case pkg.Module != nil && p.Module != nil && pkg.Module.Path == p.Module.Path:
+		return UsedByRepository
+	}
+	return UsedByDependency
+}
+
+// ImportSites returns where the package with the given import path is
+// imported by packages at usage level u, e.g. by the main package
+// for UsedByMain.
+func (p *Program) ImportSites(path string, u Usage) []Evidence {
+	if p.fset == nil {
+		p.fset = token.NewFileSet()
+		p.imports = make(map[string][]*ast.ImportSpec)
+	}
+
+	var sites []Evidence
+
+	for _, pkg := range p.Packages.AllPackages() {
+		if _, ok := pkg.Imports[path]; !ok || p.usageOf(pkg) != u {
+			continue
+		}
+
+		for _, file := range pkg.GoFiles {
+			specs, ok := p.imports[file]
+			if !ok {
+				f, err := parser.ParseFile(p.fset, file, nil, parser.ImportsOnly)
+				if err == nil {
+					specs = f.Imports
+				}
+				p.imports[file] = specs
+			}
+
+			for _, spec := range specs {
+				if imp, err := strconv.Unquote(spec.Path.Value); err != nil || imp != path {
+					continue
+				}
+
+				e := Evidence{
+					Package: pkg.PkgPath,
+					File:    file,
+					Line:    p.fset.Position(spec.Pos()).Line,
+				}
+				if pkg.Module != nil {
+					e.Module = pkg.Module.Path
+					if rel, err := filepath.Rel(pkg.Module.Dir, file); err == nil {
+						e.File = filepath.ToSlash(rel)
+					}
+				}
+				sites = append(sites, e)
+			}
+		}
+	}
+
+	return sites
+}
+
+// Group is a group of report columns.
+type Group struct {
+	Name    string
+	Columns []string
+}
+
+// columnGroups returns the column groups of the results, as given
+// by the first one.
+func columnGroups(results []Result) []Group {
+	if len(results) == 0 {
+		return nil
+	}
+
+	var gs []Group
+	for _, d := range results[0].Columns {
+		if len(gs) == 0 || gs[len(gs)-1].Name != d.Group {
+			gs = append(gs, Group{Name: d.Group})
+		}
+		g := &gs[len(gs)-1]
+		g.Columns = append(g.Columns, d.Name)
+	}
+	return gs
+}
+
+var detectors = []Detector{
+	ImportDetector("go/parser", "Input", "go/parser"),
+	ImportDetector("go/loader", "Input", "golang.org/x/tools/go/loader"),
+	ImportDetector("go/ssa", "Input", "golang.org/x/tools/go/ssa"),
+	&gometalinterDetector{},
+	&metalintDetector{},
+	ImportDetector("Checker", "Metalinter support", "github.com/mvdan/lint"),
+	ImportDetector("flag", "Options", "flag"),
+	ImportDetector("go-arg", "Options", "github.com/alexflint/go-arg..."),
+	ImportDetector("go-flags", "Options", "github.com/jessevdk/go-flags..."),
+	ImportDetector("kingpin", "Options", "gopkg.in/alecthomas/kingpin..."),
+	ImportDetector("pflag", "Options", "github.com/spf13/pflag..."),
+	ImportDetector("sflags", "Options", "github.com/octago/sflags/gen/gflag..."),
+}
+
+// RegisterDetector adds a detector to the report. Its column is
+// appended to the detector's group; a new group is added after the
+// existing ones.
+func RegisterDetector(d Detector) {
+	detectors = append(detectors, d)
+}
+
+// Detectors returns all registered detectors, ordered by group.
+func Detectors() []Detector {
+	var ds []Detector
+	for _, g := range groups() {
+		for _, d := range detectors {
+			if d.Group() == g {
+				ds = append(ds, d)
+			}
+		}
+	}
+	return ds
+}
+
+// Groups returns the column groups of all registered detectors, in
+// the same order as Detectors.
+func Groups() []Group {
+	var gs []Group
+	for _, g := range groups() {
+		group := Group{Name: g}
+		for _, d := range detectors {
+			if d.Group() == g {
+				group.Columns = append(group.Columns, d.Name())
+			}
+		}
+		gs = append(gs, group)
+	}
+	return gs
+}
+
+// groups returns the names of all groups in order of appearance.
+func groups() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, d := range detectors {
+		if !seen[d.Group()] {
+			seen[d.Group()] = true
+			names = append(names, d.Group())
+		}
+	}
+	return names
+}
+
+type importDetector struct {
+	name  string
+	group string
+	paths []string
+}
+
+// ImportDetector returns a Detector that looks for imports of any of
+// the given package paths. A path ending in "..." matches all paths
+// with that prefix, e.g. "gopkg.in/alecthomas/kingpin..." matches
+// "gopkg.in/alecthomas/kingpin.v2". Vendored packages are matched by
+// their original path.
+func ImportDetector(name, group string, paths ...string) Detector {
+	return &importDetector{name, group, paths}
+}
+
+func (d *importDetector) Name() string  { return d.name }
+func (d *importDetector) Group() string { return d.group }
+
+func (d *importDetector) Detect(p *Program) Detection {
+	var det Detection
+	var matches []string
+
+	for imp, v := range p.Imports {
+		if d.match(imp) {
+			det.Usage.add(v)
+			matches = append(matches, imp)
+		}
+	}
+
+	sort.Strings(matches)
+
+	if det.Usage != Unused {
+		for _, imp := range matches {
+			det.Evidence = append(det.Evidence, p.ImportSites(imp, det.Usage)...)
+		}
+	}
+
+	return det
+}
+
+func (d *importDetector) match(imp string) bool {
+	if i := strings.LastIndex(imp, "/vendor/"); i >= 0 {
+		imp = imp[i+len("/vendor/"):]
+	}
+	for _, path := range d.paths {
+		if imp == path || strings.HasSuffix(path, "...") && strings.HasPrefix(imp, strings.TrimSuffix(path, "...")) {
+			return true
+		}
+	}
+	return false
+}
+
+// gometalinterDetector checks whether gometalinter has a linter
+// definition for a linter's command.
+type gometalinterDetector struct {
+	defs []string
+}
+
+func (d *gometalinterDetector) Name() string  { return "gometalinter" }
+func (d *gometalinterDetector) Group() string { return "Metalinter support" }
+
+const gometalinterPath = "github.com/alecthomas/gometalinter"
+
+func (d *gometalinterDetector) Requires() []string { return []string{gometalinterPath} }
+
+func (d *gometalinterDetector) Prepare(ctx context.Context, f *fetch.Fetcher) error {
+	m, err := f.Fetch(ctx, gometalinterPath)
+	if err != nil {
+		return err
+	}
+
+	g := &gometalinter.GometalinterSource{Dir: m.Dir}
+	d.defs, err = g.GetLinterDefinitions()
+	return err
+}
+
+func (d *gometalinterDetector) Detect(p *Program) Detection {
+	for _, def := range d.defs {
+		if strings.HasPrefix(def, p.Cmd) {
+			return Detection{
+				Usage: UsedByMain,
+				Evidence: []Evidence{{
+					Package: gometalinterPath,
+					Text:    def,
+				}},
+			}
+		}
+	}
+	return Detection{}
+}
+
+// metalintDetector checks whether metalint includes a linter.
+type metalintDetector struct {
+	prog *Program
+}
+
+const metalintPath = "github.com/mvdan/lint/cmd/metalint"
+
+func (d *metalintDetector) Name() string       { return "metalint" }
+func (d *metalintDetector) Group() string      { return "Metalinter support" }
+func (d *metalintDetector) Requires() []string { return []string{metalintPath} }
+
+func (d *metalintDetector) Prepare(ctx context.Context, f *fetch.Fetcher) error {
+	m, err := f.Fetch(ctx, metalintPath)
+	if err != nil {
+		return err
+	}
+
+	p, err := loadProgram(ctx, f, linter{name: "metalint", cmd: "metalint", path: metalintPath}, m)
+	if err != nil {
+		return err
+	}
+
+	d.prog = p
+	return nil
+}
+
+func (d *metalintDetector) Detect(p *Program) Detection {
+	u, ok := d.prog.Imports[p.Path]
+	if !ok {
+		return Detection{}
+	}
+	return Detection{
+		Usage:    UsedByMain,
+		Evidence: d.prog.ImportSites(p.Path, u),
+	}
+}
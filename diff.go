@@ -0,0 +1,344 @@
+package golinters
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+)
+
+// ChangeKind tells how a linter changed between two reports.
+type ChangeKind string
+
+const (
+	// Added means the linter is new.
+	Added ChangeKind = "added"
+	// Removed means the linter is gone.
+	Removed ChangeKind = "removed"
+	// Changed means a field of the linter changed.
+	Changed ChangeKind = "changed"
+)
+
+// Change is a difference between two reports.
+type Change struct {
+	Linter string     `json:"linter"`
+	Kind   ChangeKind `json:"kind"`
+	// Field is "maintainer", "url" or the name of a detector. It
+	// is empty for added and removed linters.
+	Field string `json:"field,omitempty"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
+
+func (c Change) String() string {
+	switch c.Kind {
+	case Added:
+		return fmt.Sprintf("+ %s", c.Linter)
+	case Removed:
+		return fmt.Sprintf("- %s", c.Linter)
+	}
+	return fmt.Sprintf("~ %s: %s %q -> %q", c.Linter, c.Field, c.Old, c.New)
+}
+
+// Diff lists the changes between two reports.
+type Diff struct {
+	Version int       `json:"version"`
+	Old     time.Time `json:"old"`
+	New     time.Time `json:"new"`
+	Changes []Change  `json:"changes"`
+
+	older, newer *Report
+}
+
+// DiffReports compares two reports. Linters are matched by name,
+// capabilities by detector name. Analyzed versions aren't compared,
+// since they change all the time.
+func DiffReports(older, newer *Report) *Diff {
+	d := &Diff{
+		Version: JSONVersion,
+		Old:     older.Timestamp,
+		New:     newer.Timestamp,
+		Changes: []Change{},
+		older:   older,
+		newer:   newer,
+	}
+
+	fields := diffFields(older, newer)
+	oldByName := resultsByName(older.Results)
+	newByName := resultsByName(newer.Results)
+
+	for i := range newer.Results {
+		r := &newer.Results[i]
+		o, ok := oldByName[r.Name]
+		if !ok {
+			d.Changes = append(d.Changes, Change{Linter: r.Name, Kind: Added})
+			continue
+		}
+
+		for _, f := range fields {
+			ov, nv := f.value(o), f.value(r)
+			if ov != nv {
+				d.Changes = append(d.Changes, Change{Linter: r.Name, Kind: Changed, Field: f.name, Old: ov, New: nv})
+			}
+		}
+	}
+
+	for _, r := range older.Results {
+		if _, ok := newByName[r.Name]; !ok {
+			d.Changes = append(d.Changes, Change{Linter: r.Name, Kind: Removed})
+		}
+	}
+
+	return d
+}
+
+// Changed returns the changes of a linter.
+func (d *Diff) Changed(linter string) []Change {
+	var cs []Change
+	for _, c := range d.Changes {
+		if c.Linter == linter {
+			cs = append(cs, c)
+		}
+	}
+	return cs
+}
+
+func resultsByName(results []Result) map[string]*Result {
+	m := make(map[string]*Result)
+	for i := range results {
+		m[results[i].Name] = &results[i]
+	}
+	return m
+}
+
+// diffField is a comparable field of a result.
+type diffField struct {
+	name  string
+	group string
+	value func(r *Result) string
+}
+
+// diffFields returns the fields to compare: maintainer, URL and all
+// detectors of the newer report. Detectors only in the older report
+// follow the last detector of their group, or come last if the group
+// is gone.
+func diffFields(older, newer *Report) []diffField {
+	fields := []diffField{
+		{"maintainer", "General info", func(r *Result) string {
+			if r.Repo == nil {
+				return "?"
+			}
+			return r.Repo.Maintainer
+		}},
+		{"url", "General info", func(r *Result) string {
+			if r.Repo == nil {
+				return "?"
+			}
+			return r.Repo.URL
+		}},
+	}
+
+	seen := make(map[string]bool)
+	for i, results := range [][]Result{newer.Results, older.Results} {
+		if len(results) == 0 {
+			continue
+		}
+		for _, c := range results[0].Columns {
+			if seen[c.Name] {
+				continue
+			}
+			seen[c.Name] = true
+			name := c.Name
+			f := diffField{name, c.Group, func(r *Result) string {
+				d, ok := r.Column(name)
+				if !ok {
+					return ""
+				}
+				return d.Usage.String()
+			}}
+
+			at := len(fields)
+			if i > 0 {
+				for j := range fields {
+					if fields[j].group == c.Group {
+						at = j + 1
+					}
+				}
+			}
+			fields = append(fields[:at], append([]diffField{f}, fields[at:]...)...)
+		}
+	}
+
+	return fields
+}
+
+// WriteText writes the changes, one per line.
+func (d *Diff) WriteText(w io.Writer) error {
+	if len(d.Changes) == 0 {
+		_, err := fmt.Fprintln(w, "no changes")
+		return err
+	}
+
+	for _, c := range d.Changes {
+		if _, err := fmt.Fprintln(w, c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteJSON writes the changes as JSON.
+func (d *Diff) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
+
+// diffCell is a cell of the HTML diff.
+type diffCell struct {
+	Value   string
+	Old     string
+	Changed bool
+}
+
+// diffRow is a row of the HTML diff.
+type diffRow struct {
+	Name  string
+	Kind  ChangeKind
+	Cells []diffCell
+}
+
+// WriteHTML writes a HTML page with the new report's table, in which
+// changed cells are highlighted. Removed linters are shown with their
+// old values.
+func (d *Diff) WriteHTML(w io.Writer) error {
+	fields := diffFields(d.older, d.newer)
+
+	var groups []Group
+	for _, f := range fields {
+		if len(groups) == 0 || groups[len(groups)-1].Name != f.group {
+			groups = append(groups, Group{Name: f.group})
+		}
+		g := &groups[len(groups)-1]
+		g.Columns = append(g.Columns, f.name)
+	}
+
+	oldByName := resultsByName(d.older.Results)
+	newByName := resultsByName(d.newer.Results)
+
+	// row returns the row of linter r, compared to its old version
+	// o if given. Rows of unchanged linters have no kind.
+	row := func(kind ChangeKind, r, o *Result) diffRow {
+		dr := diffRow{Name: r.Name, Kind: kind}
+		changed := false
+		for _, f := range fields {
+			c := diffCell{Value: f.value(r)}
+			if o != nil {
+				c.Old = f.value(o)
+				c.Changed = c.Old != c.Value
+				changed = changed || c.Changed
+			}
+			dr.Cells = append(dr.Cells, c)
+		}
+		if kind == Changed && !changed {
+			dr.Kind = ""
+		}
+		return dr
+	}
+
+	var rows []diffRow
+	for _, r := range d.newer.Results {
+		if o, ok := oldByName[r.Name]; ok {
+			rows = append(rows, row(Changed, newByName[r.Name], o))
+		} else {
+			rows = append(rows, row(Added, newByName[r.Name], nil))
+		}
+	}
+	for _, r := range d.older.Results {
+		if _, ok := newByName[r.Name]; !ok {
+			rows = append(rows, row(Removed, oldByName[r.Name], nil))
+		}
+	}
+
+	data := struct {
+		Old, New string
+		Groups   []Group
+		Rows     []diffRow
+		Changes  []Change
+	}{
+		d.Old.Format(time.RFC1123),
+		d.New.Format(time.RFC1123),
+		groups,
+		rows,
+		d.Changes,
+	}
+
+	return diffTemplate.Execute(w, data)
+}
+
+var diffTemplate = template.Must(template.New("diff").Parse(`<!DOCTYPE html>
+<html>
+	<head>
+		<meta http-equiv="Content-Type" content="text/html; charset=utf-8">
+		<style>
+			html, body {
+				font-family: Arial, sans-serif;
+			}
+			tt {
+				font-family: Menlo, monospace;
+			}
+			table, th, td {
+				border: 1px solid #000;
+				border-collapse: collapse;
+			}
+			th, td {
+				padding: .33em;
+			}
+			td.changed {
+				background-color: #f5d76e;
+				font-weight: bold;
+			}
+			td .old {
+				color: #777;
+				font-weight: normal;
+				text-decoration: line-through;
+			}
+			tr.added td {
+				background-color: #a9e3a0;
+			}
+			tr.removed td {
+				background-color: #e8a0a0;
+				text-decoration: line-through;
+			}
+			.timestamp {
+				font-size: small;
+			}
+		</style>
+	</head>
+	<body>
+		<p class="timestamp">Changes from {{ .Old }} to {{ .New }}: {{ len .Changes }}</p>
+		<table>
+			<thead>
+				<tr>
+					<th rowspan="2">Name</th>
+					{{ range .Groups }}<th colspan="{{ len .Columns }}">{{ .Name }}</th>
+					{{ end -}}
+				</tr>
+				<tr>
+					{{ range .Groups }}{{ range .Columns }}<th><tt>{{ . }}</tt></th>
+					{{ end }}{{ end -}}
+				</tr>
+			</thead>
+			<tbody>
+				{{ range .Rows }}<tr class="{{ .Kind }}">
+					<td>{{ .Name }}</td>
+					{{ range .Cells }}{{ if .Changed }}<td class="changed" title="was: {{ .Old }}"><span class="old">{{ .Old }}</span> {{ .Value }}</td>{{ else }}<td>{{ .Value }}</td>{{ end }}
+					{{ end -}}
+				</tr>
+				{{ end -}}
+			</tbody>
+		</table>
+	</body>
+</html>`))
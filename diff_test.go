@@ -0,0 +1,145 @@
+package golinters
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/thomasheller/golinters/repo"
+)
+
+func TestDiffReports(t *testing.T) {
+	col := func(group, name string, u Usage, evidence ...Evidence) Detection {
+		return Detection{Name: name, Group: group, Usage: u, Evidence: evidence}
+	}
+	ssa := func(u Usage, evidence ...Evidence) Detection { return col("Packages", "go/ssa", u, evidence...) }
+	gml := func(u Usage) Detection { return col("Metalinter support", "gometalinter", u) }
+	result := func(name string, r *repo.Repository, columns ...Detection) Result {
+		return Result{Name: name, Repo: r, Columns: columns}
+	}
+	jane := &repo.Repository{Maintainer: "Jane", URL: "https://github.com/jane/a"}
+
+	tests := []struct {
+		name       string
+		old, newer []Result
+		want       []Change
+	}{
+		{
+			name:  "unchanged",
+			old:   []Result{result("a", jane, ssa(UsedByMain))},
+			newer: []Result{result("a", jane, ssa(UsedByMain))},
+			want:  []Change{},
+		},
+		{
+			name:  "added and removed",
+			old:   []Result{result("a", jane), result("b", jane)},
+			newer: []Result{result("c", jane), result("a", jane)},
+			want: []Change{
+				{Linter: "c", Kind: Added},
+				{Linter: "b", Kind: Removed},
+			},
+		},
+		{
+			name:  "maintainer and URL",
+			old:   []Result{result("a", jane)},
+			newer: []Result{result("a", &repo.Repository{Maintainer: "Joe", URL: "https://gitlab.com/joe/a"})},
+			want: []Change{
+				{Linter: "a", Kind: Changed, Field: "maintainer", Old: "Jane", New: "Joe"},
+				{Linter: "a", Kind: Changed, Field: "url", Old: "https://github.com/jane/a", New: "https://gitlab.com/joe/a"},
+			},
+		},
+		{
+			name:  "repository unknown",
+			old:   []Result{result("a", jane)},
+			newer: []Result{result("a", nil)},
+			want: []Change{
+				{Linter: "a", Kind: Changed, Field: "maintainer", Old: "Jane", New: "?"},
+				{Linter: "a", Kind: Changed, Field: "url", Old: "https://github.com/jane/a", New: "?"},
+			},
+		},
+		{
+			name:  "repositories unknown in both",
+			old:   []Result{result("a", nil)},
+			newer: []Result{result("a", nil)},
+			want:  []Change{},
+		},
+		{
+			name:  "capabilities",
+			old:   []Result{result("a", jane, ssa(Unused), gml(UsedByMain))},
+			newer: []Result{result("a", jane, ssa(UsedByDependency), gml(Unknown))},
+			want: []Change{
+				{Linter: "a", Kind: Changed, Field: "go/ssa", Old: "N", New: "dep"},
+				{Linter: "a", Kind: Changed, Field: "gometalinter", Old: "Y", New: "?"},
+			},
+		},
+		{
+			name:  "evidence only",
+			old:   []Result{result("a", jane, ssa(UsedByMain, Evidence{Package: "golang.org/x/tools/go/ssa", File: "main.go", Line: 3}))},
+			newer: []Result{result("a", jane, ssa(UsedByMain, Evidence{Package: "golang.org/x/tools/go/ssa", File: "main.go", Line: 7}))},
+			want:  []Change{},
+		},
+		{
+			name:  "detector only in one report",
+			old:   []Result{result("a", jane, ssa(UsedByMain), gml(UsedByMain))},
+			newer: []Result{result("a", jane, ssa(UsedByMain))},
+			want: []Change{
+				{Linter: "a", Kind: Changed, Field: "gometalinter", Old: "Y", New: ""},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		older := &Report{Timestamp: time.Unix(0, 0), Results: test.old}
+		newer := &Report{Timestamp: time.Unix(1, 0), Results: test.newer}
+
+		d := DiffReports(older, newer)
+		if !reflect.DeepEqual(d.Changes, test.want) {
+			t.Errorf("%s:\ngot  %+v\nwant %+v", test.name, d.Changes, test.want)
+		}
+		if !d.Old.Equal(older.Timestamp) || !d.New.Equal(newer.Timestamp) {
+			t.Errorf("%s: got timestamps %v, %v", test.name, d.Old, d.New)
+		}
+	}
+}
+
+func TestDiffFields(t *testing.T) {
+	col := func(group, name string) Detection { return Detection{Name: name, Group: group} }
+	report := func(columns ...Detection) *Report {
+		return &Report{Results: []Result{{Name: "x", Columns: columns}}}
+	}
+
+	tests := []struct {
+		name       string
+		old, newer *Report
+		want       []string
+	}{
+		{
+			name:  "same columns",
+			old:   report(col("A", "a1"), col("B", "b1")),
+			newer: report(col("A", "a1"), col("B", "b1")),
+			want:  []string{"General info/maintainer", "General info/url", "A/a1", "B/b1"},
+		},
+		{
+			name:  "old-only columns stay in their group",
+			old:   report(col("A", "a1"), col("A", "a2"), col("B", "b1"), col("C", "c1"), col("B", "b2")),
+			newer: report(col("A", "a1"), col("B", "b1"), col("D", "d1")),
+			want:  []string{"General info/maintainer", "General info/url", "A/a1", "A/a2", "B/b1", "B/b2", "D/d1", "C/c1"},
+		},
+		{
+			name:  "empty reports",
+			old:   &Report{},
+			newer: report(col("A", "a1")),
+			want:  []string{"General info/maintainer", "General info/url", "A/a1"},
+		},
+	}
+
+	for _, test := range tests {
+		var got []string
+		for _, f := range diffFields(test.old, test.newer) {
+			got = append(got, f.group+"/"+f.name)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s:\ngot  %v\nwant %v", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package golinters
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// generalColumns are the columns before the detector columns in CSV
+// and XLSX exports.
+var generalColumns = []string{"Name", "Maintainer", "Repository URL"}
+
+// WriteCSV writes results as CSV, with one row per linter and one
+// column per detector.
+func WriteCSV(w io.Writer, results []Result) error {
+	cw := csv.NewWriter(w)
+
+	header := append([]string{}, generalColumns...)
+	for _, g := range columnGroups(results) {
+		header = append(header, g.Columns...)
+	}
+	header = append(header, "Notes", "Errors")
+
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		if err := cw.Write(exportRow(r)); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// exportRow returns the cells of a CSV or XLSX row.
+func exportRow(r Result) []string {
+	row := []string{r.Name, "", ""}
+	if r.Repo != nil {
+		row[1], row[2] = r.Repo.Maintainer, r.Repo.URL
+	}
+	for _, d := range r.Columns {
+		row = append(row, d.Usage.String())
+	}
+	return append(row, r.Notes, strings.Join(r.Errors, "; "))
+}
+
+// xlsxFills are the cell colors of the XLSX export, as in the HTML
+// report.
+var xlsxFills = map[Usage]string{
+	UsedByMain:       "5BD64A",
+	UsedByRepository: "A9E3A0",
+	UsedByDependency: "E6D36E",
+	Unused:           "D64A4A",
+	Unknown:          "C8C8C8",
+}
+
+// WriteXLSX writes results as an Excel workbook. Like in the HTML
+// report, the detector columns are grouped under merged header cells.
+func WriteXLSX(w io.Writer, results []Result) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	const sheet = "golinters"
+
+	if err := f.SetSheetName(f.GetSheetName(0), sheet); err != nil {
+		return err
+	}
+
+	bold, err := f.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true},
+		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
+	})
+	if err != nil {
+		return err
+	}
+
+	fills := make(map[Usage]int)
+	for u, color := range xlsxFills {
+		fills[u], err = f.NewStyle(&excelize.Style{
+			Fill:      excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{color}},
+			Alignment: &excelize.Alignment{Horizontal: "center"},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// set writes a value and style to the cell at column col (0-based)
+	// and row row (1-based).
+	set := func(col, row int, value interface{}, style int) error {
+		cell, err := excelize.CoordinatesToCellName(col+1, row)
+		if err != nil {
+			return err
+		}
+		if err := f.SetCellValue(sheet, cell, value); err != nil {
+			return err
+		}
+		if style != 0 {
+			return f.SetCellStyle(sheet, cell, cell, style)
+		}
+		return nil
+	}
+
+	// merge merges the header cells from (col1, row1) to (col2, row2).
+	merge := func(col1, row1, col2, row2 int) error {
+		from, err := excelize.CoordinatesToCellName(col1+1, row1)
+		if err != nil {
+			return err
+		}
+		to, err := excelize.CoordinatesToCellName(col2+1, row2)
+		if err != nil {
+			return err
+		}
+		if from == to {
+			return nil
+		}
+		return f.MergeCell(sheet, from, to)
+	}
+
+	// header: group names in row 1, column names in row 2
+	col := 0
+	if err := set(col, 1, "General info", bold); err != nil {
+		return err
+	}
+	if err := merge(col, 1, col+len(generalColumns)-1, 1); err != nil {
+		return err
+	}
+	for _, c := range generalColumns {
+		if err := set(col, 2, c, bold); err != nil {
+			return err
+		}
+		col++
+	}
+
+	for _, g := range columnGroups(results) {
+		if err := set(col, 1, g.Name, bold); err != nil {
+			return err
+		}
+		if err := merge(col, 1, col+len(g.Columns)-1, 1); err != nil {
+			return err
+		}
+		for _, c := range g.Columns {
+			if err := set(col, 2, c, bold); err != nil {
+				return err
+			}
+			col++
+		}
+	}
+
+	for _, c := range []string{"Notes", "Errors"} {
+		if err := set(col, 1, c, bold); err != nil {
+			return err
+		}
+		if err := merge(col, 1, col, 2); err != nil {
+			return err
+		}
+		col++
+	}
+
+	for i, r := range results {
+		row := i + 3
+		for j, value := range exportRow(r) {
+			style := 0
+			if k := j - len(generalColumns); k >= 0 && k < len(r.Columns) {
+				style = fills[r.Columns[k].Usage]
+			}
+			if err := set(j, row, value, style); err != nil {
+				return err
+			}
+		}
+
+		if r.Repo != nil {
+			cell, err := excelize.CoordinatesToCellName(3, row)
+			if err != nil {
+				return err
+			}
+			if err := f.SetCellHyperLink(sheet, cell, r.Repo.URL, "External"); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := f.SetPanes(sheet, &excelize.Panes{
+		Freeze:      true,
+		XSplit:      1,
+		YSplit:      2,
+		TopLeftCell: "B3",
+		ActivePane:  "bottomRight",
+	}); err != nil {
+		return err
+	}
+
+	return f.Write(w)
+}
@@ -0,0 +1,279 @@
+// Package fetch downloads Go packages and their dependencies into a
+// cache directory owned by golinters. It uses the go command in
+// module mode, so the user's GOPATH and module cache stay untouched,
+// and GOPROXY (including file:// proxies for offline use), GOSUMDB,
+// GOPRIVATE and friends are respected.
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/module"
+
+	"github.com/thomasheller/golinters/load"
+	"github.com/thomasheller/golinters/repo"
+)
+
+// Fetcher downloads packages into a cache directory.
+type Fetcher struct {
+	// Dir is the cache directory. If empty, DefaultDir is used.
+	Dir string
+	// Env is the environment the go command runs in, in addition
+	// to the settings Fetcher needs. If nil, the current
+	// environment is used.
+	Env []string
+	// Offline makes Fetch use what was fetched before instead of
+	// downloading the latest version. Nothing is downloaded, and
+	// packages that weren't fetched before can't be fetched.
+	Offline bool
+	// Resolver finds the repository roots of fetched modules. If
+	// nil, repo.DefaultResolver is used.
+	Resolver *repo.Resolver
+}
+
+// Module describes where the source of a fetched package is.
+type Module struct {
+	// Path is the module path of the package.
+	Path string `json:"path"`
+	// Version is the module version that was fetched.
+	Version string `json:"version"`
+	// Dir is the module's source directory in the cache.
+	Dir string `json:"dir"`
+	// Workspace is a directory with a go.mod file that requires
+	// the module. The package can be loaded from there.
+	Workspace string `json:"workspace"`
+	// Root is the import path of the repository root, or empty if
+	// it couldn't be determined, see repo.Resolver.
+	Root string `json:"root,omitempty"`
+}
+
+// Subdir returns the directory of the module relative to the
+// repository root, in slash-separated form. It is "" for modules at
+// the root. A major version suffix isn't part of the directory, as
+// is usual for modules of major version 2 and up.
+func (m *Module) Subdir() string {
+	prefix, _, _ := module.SplitPathVersion(m.Path)
+	if m.Root == "" || !strings.HasPrefix(prefix+"/", m.Root+"/") {
+		return ""
+	}
+	return strings.TrimPrefix(strings.TrimPrefix(prefix, m.Root), "/")
+}
+
+// Revision returns the VCS revision of the fetched version: the
+// commit hash for pseudo-versions, otherwise the tag, which is
+// prefixed with the module's Subdir for nested modules.
+func (m *Module) Revision() string {
+	if module.IsPseudoVersion(m.Version) {
+		if rev, err := module.PseudoVersionRev(m.Version); err == nil {
+			return rev
+		}
+	}
+	tag := strings.TrimSuffix(m.Version, "+incompatible")
+	if dir := m.Subdir(); dir != "" {
+		tag = dir + "/" + tag
+	}
+	return tag
+}
+
+// DefaultDir returns the default cache directory, which is a
+// "golinters" directory in the user's cache directory.
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "golinters"), nil
+}
+
+// CacheDir returns the absolute path of the cache directory.
+func (f *Fetcher) CacheDir() (string, error) {
+	if f.Dir != "" {
+		return filepath.Abs(f.Dir)
+	}
+	return DefaultDir()
+}
+
+// goflags returns the GOFLAGS of env with -mod=mod added, replacing
+// any -mod flag of the user. Other flags, e.g. -modcacherw, are kept.
+func goflags(env []string) string {
+	var user string
+	for _, kv := range env {
+		if strings.HasPrefix(kv, "GOFLAGS=") {
+			user = strings.TrimPrefix(kv, "GOFLAGS=")
+		}
+	}
+
+	flags := []string{}
+	for _, f := range strings.Fields(user) {
+		if !strings.HasPrefix(f, "-mod=") && !strings.HasPrefix(f, "--mod=") {
+			flags = append(flags, f)
+		}
+	}
+
+	return strings.Join(append(flags, "-mod=mod"), " ")
+}
+
+// Environ returns the environment the go command runs in when
+// fetching or loading packages from the cache.
+func (f *Fetcher) Environ() ([]string, error) {
+	dir, err := f.CacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	env := f.Env
+	if env == nil {
+		env = os.Environ()
+	}
+
+	// later entries take precedence
+	env = append(env[:len(env):len(env)],
+		"GO111MODULE=on",
+		"GOFLAGS="+goflags(env),
+		"GOWORK=off",
+		"GOMODCACHE="+filepath.Join(dir, "mod"),
+	)
+
+	if f.Offline {
+		env = append(env, "GOPROXY=off")
+	}
+
+	return env, nil
+}
+
+// Fetch downloads the latest version of the module that provides
+// the package with the given import path. If f is offline, the
+// version fetched before is used.
+func (f *Fetcher) Fetch(ctx context.Context, path string) (*Module, error) {
+	dir, err := f.CacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	env, err := f.Environ()
+	if err != nil {
+		return nil, err
+	}
+
+	ws := filepath.Join(dir, "work", filepath.FromSlash(path))
+	gomod := filepath.Join(ws, "go.mod")
+
+	if f.Offline {
+		if _, err := os.Stat(gomod); err != nil {
+			return nil, fmt.Errorf("%s hasn't been fetched", path)
+		}
+	}
+
+	if !f.Offline {
+		_, err := os.Stat(ws)
+		created := os.IsNotExist(err)
+
+		if err := os.MkdirAll(ws, 0755); err != nil {
+			return nil, err
+		}
+
+		if created {
+			if err := f.record(path, ws); err != nil {
+				return nil, err
+			}
+		}
+
+		if _, err := os.Stat(gomod); os.IsNotExist(err) {
+			if err := ioutil.WriteFile(gomod, []byte("module golinters.local/work\n"), 0644); err != nil {
+				return nil, err
+			}
+		}
+
+		if _, err := gocmd(ctx, ws, env, "get", path+"@latest"); err != nil {
+			return nil, err
+		}
+	}
+
+	out, err := gocmd(ctx, ws, env, "list", "-json=Module", path)
+	if err != nil {
+		return nil, err
+	}
+
+	var p struct {
+		Module *struct {
+			Path    string
+			Version string
+			Dir     string
+		}
+	}
+
+	if err := json.Unmarshal(out, &p); err != nil {
+		return nil, err
+	}
+
+	if p.Module == nil {
+		return nil, fmt.Errorf("%s is not provided by a module", path)
+	}
+
+	m := &Module{
+		Path:      p.Module.Path,
+		Version:   p.Module.Version,
+		Dir:       p.Module.Dir,
+		Workspace: ws,
+	}
+
+	if !f.Offline {
+		resolver := f.Resolver
+		if resolver == nil {
+			resolver = repo.DefaultResolver
+		}
+		if root, err := resolver.Resolve(ctx, m.Path); err == nil {
+			m.Root = root.Path
+		}
+	}
+
+	// The module cache is shared by all packages, so only the
+	// module's own directory is recorded.
+	if !f.Offline && within(m.Dir, filepath.Join(dir, "mod")) {
+		if err := f.record(path, m.Dir); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// LoadConfig returns a configuration to load the fetched package.
+func (f *Fetcher) LoadConfig(m *Module) (load.Config, error) {
+	env, err := f.Environ()
+	if err != nil {
+		return load.Config{}, err
+	}
+
+	return load.Config{Dir: m.Workspace, Env: env}, nil
+}
+
+// gocmd runs the go command and returns its standard output. The
+// error includes the go command's standard error.
+func gocmd(ctx context.Context, dir string, env []string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+
+	c := exec.CommandContext(ctx, "go", args...)
+	c.Dir = dir
+	c.Env = env
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+
+	if err := c.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			return nil, fmt.Errorf("go %s: %v", strings.Join(args, " "), err)
+		}
+		return nil, fmt.Errorf("go %s: %v\n%s", strings.Join(args, " "), err, msg)
+	}
+
+	return stdout.Bytes(), nil
+}
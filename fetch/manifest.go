@@ -0,0 +1,307 @@
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/thomasheller/golinters/repo"
+)
+
+// manifestFile is the name of the manifest in the cache directory.
+const manifestFile = "manifest.json"
+
+// Manifest records the directories a Fetcher created, and which
+// packages they were fetched for, so they can be removed safely.
+type Manifest struct {
+	Entries []ManifestEntry `json:"entries"`
+}
+
+// ManifestEntry is a directory created by a Fetcher.
+type ManifestEntry struct {
+	Dir string `json:"dir"`
+	// Packages are the import paths the directory was fetched for.
+	Packages []string  `json:"packages"`
+	Created  time.Time `json:"created"`
+}
+
+// entry returns the entry of dir, or nil.
+func (m *Manifest) entry(dir string) *ManifestEntry {
+	for i := range m.Entries {
+		if m.Entries[i].Dir == dir {
+			return &m.Entries[i]
+		}
+	}
+	return nil
+}
+
+// add records that dir was fetched for the package with the given
+// import path.
+func (m *Manifest) add(dir, path string) {
+	e := m.entry(dir)
+	if e == nil {
+		m.Entries = append(m.Entries, ManifestEntry{Dir: dir, Created: time.Now().UTC().Truncate(time.Second)})
+		e = &m.Entries[len(m.Entries)-1]
+	}
+
+	for _, p := range e.Packages {
+		if p == path {
+			return
+		}
+	}
+
+	e.Packages = append(e.Packages, path)
+	sort.Strings(e.Packages)
+}
+
+// Manifest returns the manifest of the cache directory. It is empty
+// if nothing was fetched yet.
+func (f *Fetcher) Manifest() (*Manifest, error) {
+	dir, err := f.CacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, manifestFile))
+	if os.IsNotExist(err) {
+		return &Manifest{Entries: []ManifestEntry{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%s: %v", manifestFile, err)
+	}
+
+	return &m, nil
+}
+
+func (f *Fetcher) saveManifest(m *Manifest) error {
+	dir, err := f.CacheDir()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(dir, manifestFile), append(data, '\n'), 0644)
+}
+
+// record adds dir to the manifest as fetched for the package with the
+// given import path.
+func (f *Fetcher) record(path, dir string) error {
+	man, err := f.Manifest()
+	if err != nil {
+		return err
+	}
+
+	man.add(dir, path)
+
+	return f.saveManifest(man)
+}
+
+// Removal is a directory that is removed, or kept for Reason.
+type Removal struct {
+	Dir string
+	// Packages are the import paths Dir was fetched for.
+	Packages []string
+	// Reason tells why Dir is kept. It is empty if Dir is removed.
+	Reason string
+}
+
+func (r Removal) String() string {
+	if r.Reason != "" {
+		return fmt.Sprintf("keeping %s: %s", r.Dir, r.Reason)
+	}
+	return fmt.Sprintf("removing %s", r.Dir)
+}
+
+// Remove removes the directories fetched for the packages with the
+// given import paths. Only directories in the manifest are removed,
+// and only if they weren't fetched for other packages too, don't
+// contain directories of other packages and have no uncommitted
+// changes. If dryRun is true, nothing is removed. The result lists
+// all directories of the packages, and why they are kept, if so.
+func (f *Fetcher) Remove(ctx context.Context, paths []string, dryRun bool) ([]Removal, error) {
+	man, err := f.Manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	remove := make(map[string]bool)
+	for _, p := range paths {
+		remove[p] = true
+	}
+
+	var removals []Removal
+	found := make(map[string]bool)
+
+	for _, e := range man.Entries {
+		var others []string
+		matched := false
+		for _, p := range e.Packages {
+			if remove[p] {
+				matched = true
+				found[p] = true
+			} else {
+				others = append(others, p)
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		r := Removal{Dir: e.Dir, Packages: e.Packages}
+
+		switch {
+		case len(others) > 0:
+			r.Reason = "also fetched for " + strings.Join(others, ", ")
+		default:
+			r.Reason = nested(man, e.Dir, remove)
+		}
+
+		if r.Reason == "" {
+			dirty, err := uncommitted(ctx, e.Dir)
+			if err != nil {
+				r.Reason = err.Error()
+			} else if dirty {
+				r.Reason = "has uncommitted changes"
+			}
+		}
+
+		removals = append(removals, r)
+	}
+
+	for _, p := range paths {
+		if !found[p] {
+			removals = append(removals, Removal{Packages: []string{p}, Reason: "not fetched by golinters"})
+		}
+	}
+
+	if dryRun {
+		return removals, nil
+	}
+
+	var kept []ManifestEntry
+	removed := make(map[string]bool)
+
+	for _, r := range removals {
+		if r.Reason != "" || r.Dir == "" {
+			continue
+		}
+		if err := removeAll(r.Dir); err != nil {
+			return removals, err
+		}
+		removed[r.Dir] = true
+		f.prune(filepath.Dir(r.Dir))
+	}
+
+	for _, e := range man.Entries {
+		if !removed[e.Dir] {
+			kept = append(kept, e)
+		}
+	}
+
+	man.Entries = kept
+	if man.Entries == nil {
+		man.Entries = []ManifestEntry{}
+	}
+
+	return removals, f.saveManifest(man)
+}
+
+// nested returns why dir can't be removed because it contains the
+// directory of a package that isn't removed, or "".
+func nested(man *Manifest, dir string, remove map[string]bool) string {
+	for _, e := range man.Entries {
+		if e.Dir == dir || !within(e.Dir, dir) {
+			continue
+		}
+		for _, p := range e.Packages {
+			if !remove[p] {
+				return fmt.Sprintf("contains %s, fetched for %s", e.Dir, p)
+			}
+		}
+	}
+	return ""
+}
+
+// within reports whether path is dir or inside it.
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// uncommitted reports whether dir is in a Git or Mercurial working
+// copy with uncommitted changes. Other version control systems can't
+// be checked, so an error is returned for them.
+func uncommitted(ctx context.Context, dir string) (bool, error) {
+	_, vcs, err := repo.WorkingCopy(dir)
+	if err == repo.ErrNoWorkingCopy {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	var args []string // lists changes in the current directory
+	switch vcs {
+	case "git":
+		args = []string{"git", "status", "--porcelain", "--", "."}
+	case "hg":
+		args = []string{"hg", "status", "."}
+	default:
+		return false, fmt.Errorf("can't check %s working copy for uncommitted changes", vcs)
+	}
+
+	var stdout bytes.Buffer
+	c := exec.CommandContext(ctx, args[0], args[1:]...)
+	c.Dir = dir
+	c.Stdout = &stdout
+	if err := c.Run(); err != nil {
+		return false, fmt.Errorf("can't check for uncommitted changes: %s: %v", strings.Join(args, " "), err)
+	}
+
+	return stdout.Len() > 0, nil
+}
+
+// prune removes dir and its parents while they are empty, up to the
+// cache directory.
+func (f *Fetcher) prune(dir string) {
+	cache, err := f.CacheDir()
+	if err != nil {
+		return
+	}
+
+	for within(dir, cache) && dir != cache {
+		if os.Remove(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// removeAll removes dir like os.RemoveAll, but first makes it
+// writable, since the go command makes the module cache read-only.
+func removeAll(dir string) error {
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.IsDir() {
+			os.Chmod(path, info.Mode()|0200)
+		}
+		return nil
+	})
+	return os.RemoveAll(dir)
+}
@@ -0,0 +1,413 @@
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// cacheDirs are the directories of the test cache, relative to it,
+// and the packages they were fetched for.
+var cacheDirs = []struct {
+	dir      string
+	packages []string
+}{
+	{"src/a", []string{"a.org/a"}},
+	{"src/shared", []string{"b.org/b", "c.org/c"}},
+	{"src/outer", []string{"d.org/d"}},
+	{"src/outer/inner", []string{"e.org/e"}},
+	{"src/dirty", []string{"f.org/f"}},
+	{"src/clean", []string{"g.org/g"}},
+	{"mod/h.org/h@v1.0.0", []string{"h.org/h"}},
+}
+
+// newCache returns a Fetcher for a temporary cache with the
+// directories of cacheDirs in its manifest. src/dirty and src/clean
+// are Git checkouts, the former with uncommitted changes, and the
+// module directory is read-only like in the module cache.
+func newCache(t *testing.T) *Fetcher {
+	f := &Fetcher{Dir: t.TempDir()}
+
+	for _, d := range cacheDirs {
+		dir := filepath.Join(f.Dir, filepath.FromSlash(d.dir))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range d.packages {
+			if err := f.record(p, dir); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	for _, name := range []string{"dirty", "clean"} {
+		dir := filepath.Join(f.Dir, "src", name)
+		git(t, dir, "init", "-q")
+		git(t, dir, "add", ".")
+		git(t, dir, "commit", "-q", "-m", "initial")
+	}
+	if err := ioutil.WriteFile(filepath.Join(f.Dir, "src", "dirty", "main.go"), []byte("package changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mod := filepath.Join(f.Dir, "mod", "h.org", "h@v1.0.0")
+	if err := os.Chmod(mod, 0555); err != nil {
+		t.Fatal(err)
+	}
+
+	return f
+}
+
+func git(t *testing.T, dir string, args ...string) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)
+	c := exec.Command("git", args...)
+	c.Dir = dir
+	if out, err := c.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestRemove(t *testing.T) {
+	tests := []struct {
+		name  string
+		paths []string
+		// want maps the directories of the result to why they are
+		// kept ("" if removed), "-" for packages that weren't
+		// fetched.
+		want map[string]string
+		// gone are other directories that don't exist afterwards,
+		// e.g. pruned parents.
+		gone []string
+	}{
+		{
+			name:  "removed",
+			paths: []string{"a.org/a"},
+			want:  map[string]string{"src/a": ""},
+		},
+		{
+			name:  "shared with another package",
+			paths: []string{"b.org/b"},
+			want:  map[string]string{"src/shared": "also fetched for c.org/c"},
+		},
+		{
+			name:  "shared with a removed package",
+			paths: []string{"b.org/b", "c.org/c"},
+			want:  map[string]string{"src/shared": ""},
+		},
+		{
+			name:  "nested directory is kept",
+			paths: []string{"d.org/d"},
+			want:  map[string]string{"src/outer": "contains {cache}/src/outer/inner, fetched for e.org/e"},
+		},
+		{
+			name:  "nested directory only",
+			paths: []string{"e.org/e"},
+			want:  map[string]string{"src/outer/inner": ""},
+		},
+		{
+			name:  "nested directory removed too",
+			paths: []string{"e.org/e", "d.org/d"},
+			want:  map[string]string{"src/outer": "", "src/outer/inner": ""},
+		},
+		{
+			name:  "dirty git checkout",
+			paths: []string{"f.org/f"},
+			want:  map[string]string{"src/dirty": "has uncommitted changes"},
+		},
+		{
+			name:  "clean git checkout",
+			paths: []string{"g.org/g"},
+			want:  map[string]string{"src/clean": ""},
+		},
+		{
+			name:  "read-only module, empty parents are pruned",
+			paths: []string{"h.org/h"},
+			want:  map[string]string{"mod/h.org/h@v1.0.0": ""},
+			gone:  []string{"mod"},
+		},
+		{
+			name:  "not fetched",
+			paths: []string{"x.org/x"},
+			want:  map[string]string{"x.org/x": "-"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := newCache(t)
+
+			removals, err := f.Remove(context.Background(), test.paths, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make(map[string]string)
+			for _, r := range removals {
+				if r.Dir == "" {
+					got[r.Packages[0]] = "-"
+					continue
+				}
+				rel, _ := filepath.Rel(f.Dir, r.Dir)
+				got[filepath.ToSlash(rel)] = r.Reason
+			}
+			want := make(map[string]string)
+			for dir, reason := range test.want {
+				want[dir] = strings.Replace(reason, "{cache}", f.Dir, -1)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+
+			man, err := f.Manifest()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, d := range cacheDirs {
+				dir := filepath.Join(f.Dir, filepath.FromSlash(d.dir))
+				reason, ok := want[d.dir]
+				removed := ok && reason == ""
+				if exists(dir) == removed {
+					t.Errorf("%s: exists = %v, want %v", d.dir, !removed, removed)
+				}
+				if (man.entry(dir) != nil) == removed {
+					t.Errorf("%s: in manifest = %v, want %v", d.dir, !removed, removed)
+				}
+			}
+
+			for _, dir := range test.gone {
+				if exists(filepath.Join(f.Dir, dir)) {
+					t.Errorf("%s wasn't removed", dir)
+				}
+			}
+		})
+	}
+}
+
+func TestRemoveDryRun(t *testing.T) {
+	f := newCache(t)
+
+	before, err := ioutil.ReadFile(filepath.Join(f.Dir, manifestFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	for _, d := range cacheDirs {
+		paths = append(paths, d.packages...)
+	}
+
+	removals, err := f.Remove(context.Background(), paths, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	removable := 0
+	for _, r := range removals {
+		if r.Reason == "" {
+			removable++
+		}
+	}
+	if want := len(cacheDirs) - 1; removable != want {
+		t.Errorf("got %d removable directories, want %d (all but src/dirty): %v", removable, want, removals)
+	}
+
+	for _, d := range cacheDirs {
+		if !exists(filepath.Join(f.Dir, filepath.FromSlash(d.dir), "main.go")) {
+			t.Errorf("%s was removed", d.dir)
+		}
+	}
+
+	after, err := ioutil.ReadFile(filepath.Join(f.Dir, manifestFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Errorf("manifest changed:\n%s\nwant:\n%s", after, before)
+	}
+}
+
+func TestNested(t *testing.T) {
+	man := &Manifest{}
+	man.add(filepath.FromSlash("/c/src/outer"), "d.org/d")
+	man.add(filepath.FromSlash("/c/src/outer/inner"), "e.org/e")
+	man.add(filepath.FromSlash("/c/src/outer/inner/deep"), "f.org/f")
+	man.add(filepath.FromSlash("/c/src/outerside"), "g.org/g")
+
+	tests := []struct {
+		dir    string
+		remove []string
+		want   string
+	}{
+		{"/c/src/outer", []string{"d.org/d"}, "contains /c/src/outer/inner, fetched for e.org/e"},
+		{"/c/src/outer", []string{"d.org/d", "e.org/e"}, "contains /c/src/outer/inner/deep, fetched for f.org/f"},
+		{"/c/src/outer/inner/deep", []string{"f.org/f"}, ""},
+		// /c/src/outerside has a common prefix, but isn't nested.
+		{"/c/src/outer", []string{"d.org/d", "e.org/e", "f.org/f"}, ""},
+		{"/c/src/outerside", []string{"g.org/g"}, ""},
+	}
+
+	for _, test := range tests {
+		remove := make(map[string]bool)
+		for _, p := range test.remove {
+			remove[p] = true
+		}
+		dir := filepath.FromSlash(test.dir)
+		if got := nested(man, dir, remove); got != filepath.FromSlash(test.want) {
+			t.Errorf("nested(%s, %v) = %q, want %q", test.dir, test.remove, got, test.want)
+		}
+	}
+}
+
+func TestWithin(t *testing.T) {
+	tests := []struct {
+		path, dir string
+		want      bool
+	}{
+		{"/c", "/c", true},
+		{"/c/src", "/c", true},
+		{"/c/src/a/b", "/c", true},
+		{"/c/src/../mod", "/c", true},
+		{"/", "/c", false},
+		{"/d", "/c", false},
+		{"/cc", "/c", false},
+		{"/c/../d", "/c", false},
+		{"/c/..x", "/c", true},
+		{"c/src", "/c", false},
+	}
+
+	for _, test := range tests {
+		if got := within(filepath.FromSlash(test.path), filepath.FromSlash(test.dir)); got != test.want {
+			t.Errorf("within(%s, %s) = %v, want %v", test.path, test.dir, got, test.want)
+		}
+	}
+}
+
+func TestPrune(t *testing.T) {
+	tests := []struct {
+		name string
+		// dirs are created, files get a file.
+		dirs, files []string
+		prune       string
+		// want are the directories left afterwards.
+		want []string
+	}{
+		{
+			name:  "empty parents",
+			dirs:  []string{"mod/a.org/x/y"},
+			prune: "mod/a.org/x/y",
+			want:  []string{},
+		},
+		{
+			name:  "stops at a non-empty directory",
+			dirs:  []string{"mod/a.org/x/y", "mod/b.org"},
+			prune: "mod/a.org/x/y",
+			want:  []string{"mod", "mod/b.org"},
+		},
+		{
+			name:  "stops at a file",
+			dirs:  []string{"mod/a.org/x/y"},
+			files: []string{"mod/a.org/f"},
+			prune: "mod/a.org/x/y",
+			want:  []string{"mod", "mod/a.org"},
+		},
+		{
+			name:  "non-empty directory is kept",
+			dirs:  []string{"mod/a.org/x/y"},
+			prune: "mod/a.org/x",
+			want:  []string{"mod", "mod/a.org", "mod/a.org/x", "mod/a.org/x/y"},
+		},
+		{
+			name:  "outside of the cache",
+			prune: "..",
+			want:  []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			base := t.TempDir()
+			f := &Fetcher{Dir: filepath.Join(base, "cache")}
+
+			if err := os.MkdirAll(f.Dir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			for _, d := range test.dirs {
+				if err := os.MkdirAll(filepath.Join(f.Dir, filepath.FromSlash(d)), 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, file := range test.files {
+				if err := ioutil.WriteFile(filepath.Join(f.Dir, filepath.FromSlash(file)), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			f.prune(filepath.Join(f.Dir, filepath.FromSlash(test.prune)))
+
+			if !exists(f.Dir) {
+				t.Fatalf("cache directory was removed")
+			}
+
+			got := []string{}
+			filepath.Walk(f.Dir, func(path string, info os.FileInfo, err error) error {
+				if err == nil && info.IsDir() && path != f.Dir {
+					rel, _ := filepath.Rel(f.Dir, path)
+					got = append(got, filepath.ToSlash(rel))
+				}
+				return nil
+			})
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRemoveAll(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "h.org", "h@v1.0.0")
+	sub := filepath.Join(dir, "sub")
+
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(sub, "f.go"), nil, 0444); err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range []string{sub, dir} {
+		if err := os.Chmod(d, 0555); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := removeAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if exists(dir) {
+		t.Errorf("%s wasn't removed", dir)
+	}
+	if !exists(filepath.Dir(dir)) {
+		t.Errorf("parent of %s was removed", dir)
+	}
+
+	if err := removeAll(dir); err != nil {
+		t.Errorf("removing %s again: %v", dir, err)
+	}
+}
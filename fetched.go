@@ -0,0 +1,163 @@
+package golinters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/thomasheller/golinters/fetch"
+)
+
+// Requirer is implemented by detectors that need packages besides
+// the linters, e.g. to Prepare. Fetch fetches them along with the
+// linters.
+type Requirer interface {
+	Requires() []string
+}
+
+// Fetched is the outcome of Fetch: the linters from the registry, and
+// where they were fetched to. It is written as JSON by WriteFetched,
+// so linters can be fetched once and analyzed many times.
+type Fetched struct {
+	// Version is JSONVersion at the time the file was written.
+	Version int `json:"version"`
+	// Timestamp is when the linters were fetched.
+	Timestamp time.Time `json:"timestamp"`
+	// CacheDir is the absolute path of the directory the linters
+	// were fetched to.
+	CacheDir string          `json:"cacheDir"`
+	Linters  []FetchedLinter `json:"linters"`
+}
+
+// FetchedLinter is a linter from the registry, and the module it was
+// fetched to.
+type FetchedLinter struct {
+	Name  string   `json:"name"`
+	Cmd   string   `json:"cmd"`
+	Path  string   `json:"path"`
+	Tags  []string `json:"tags,omitempty"`
+	Notes string   `json:"notes,omitempty"`
+	// Module is where the linter was fetched to, unless it
+	// couldn't be fetched.
+	Module *fetch.Module `json:"module,omitempty"`
+	// Error tells why the linter couldn't be fetched.
+	Error string `json:"error,omitempty"`
+}
+
+func (fl FetchedLinter) linter() linter {
+	return linter{name: fl.Name, cmd: fl.Cmd, path: fl.Path, comment: fl.Notes, tags: fl.Tags}
+}
+
+// Fetch downloads the latest versions of all linters in the registry
+// and Options.Add that are selected by Options.Filter, and the
+// packages needed by detectors that implement Requirer. Linters that
+// can't be fetched are part of the result, with the reason in
+// FetchedLinter.Error. An error is only returned if the registry
+// can't be loaded or ctx is done.
+func Fetch(ctx context.Context, opts Options) (*Fetched, error) {
+	linters, err := opts.linters()
+	if err != nil {
+		return nil, err
+	}
+
+	fetcher := &fetch.Fetcher{Dir: opts.CacheDir}
+
+	cacheDir, err := fetcher.CacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	opts.logger().Println("Fetching the latest versions of the linters...")
+
+	fls, err := fetchLinters(ctx, fetcher, linters, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &Fetched{
+		Version:   JSONVersion,
+		Timestamp: time.Now().UTC(),
+		CacheDir:  cacheDir,
+		Linters:   fls,
+	}
+
+	detectors := opts.Detectors
+	if detectors == nil {
+		detectors = Detectors()
+	}
+
+	for _, d := range detectors {
+		r, ok := d.(Requirer)
+		if !ok {
+			continue
+		}
+		for _, path := range r.Requires() {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if _, err := fetcher.Fetch(ctx, path); err != nil {
+				opts.logger().Printf("Error fetching %s for %s detector: %v\n", path, d.Name(), err)
+			}
+		}
+	}
+
+	return f, nil
+}
+
+// fetchLinters fetches linters. Linters that can't be fetched are
+// part of the result, with the reason in FetchedLinter.Error.
+func fetchLinters(ctx context.Context, fetcher *fetch.Fetcher, linters []linter, opts Options) ([]FetchedLinter, error) {
+	logger := opts.logger()
+	progress := opts.progress()
+
+	fls := []FetchedLinter{}
+
+	for i, l := range linters {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		progress("fetch", l.name, i, len(linters))
+		logger.Println(l.name)
+
+		fl := FetchedLinter{Name: l.name, Cmd: l.cmd, Path: l.path, Tags: l.tags, Notes: l.comment}
+
+		m, err := fetcher.Fetch(ctx, l.path)
+		if err != nil {
+			logger.Printf("Error fetching %s: %v\n", l.name, err)
+			fl.Error = err.Error()
+		} else {
+			fl.Module = m
+		}
+
+		fls = append(fls, fl)
+	}
+
+	return fls, nil
+}
+
+// WriteFetched writes the outcome of Fetch as JSON.
+func WriteFetched(w io.Writer, f *Fetched) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(f)
+}
+
+// ReadFetched reads a file written by WriteFetched. Files of other
+// versions are rejected.
+func ReadFetched(r io.Reader) (*Fetched, error) {
+	var f Fetched
+
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, err
+	}
+
+	if f.Version != JSONVersion {
+		return nil, fmt.Errorf("unsupported version %d (want %d)", f.Version, JSONVersion)
+	}
+
+	return &f, nil
+}
@@ -0,0 +1,73 @@
+package golinters
+
+import (
+	"fmt"
+	"path"
+)
+
+// Filter selects linters by name or tag. Each pattern is a glob
+// pattern as in path.Match, e.g. "go*", and matches a linter if it
+// matches its name or any of its tags.
+type Filter struct {
+	// Only selects the linters that match any of these patterns.
+	// If empty, all linters are selected.
+	Only []string
+	// Exclude deselects the linters that match any of these
+	// patterns, even if selected by Only.
+	Exclude []string
+}
+
+// Validate reports malformed patterns.
+func (f Filter) Validate() error {
+	for _, p := range append(f.Only[:len(f.Only):len(f.Only)], f.Exclude...) {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("malformed pattern %q", p)
+		}
+	}
+	return nil
+}
+
+// Match reports whether a linter with the given name and tags is
+// selected.
+func (f Filter) Match(name string, tags []string) bool {
+	if len(f.Only) > 0 && !matchAny(f.Only, name, tags) {
+		return false
+	}
+	return !matchAny(f.Exclude, name, tags)
+}
+
+func matchAny(patterns []string, name string, tags []string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+		for _, t := range tags {
+			if ok, _ := path.Match(p, t); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Results returns the selected results.
+func (f Filter) Results(results []Result) []Result {
+	var selected []Result
+	for _, r := range results {
+		if f.Match(r.Name, r.Tags) {
+			selected = append(selected, r)
+		}
+	}
+	return selected
+}
+
+// linters returns the selected linters.
+func (f Filter) linters(linters []linter) []linter {
+	var selected []linter
+	for _, l := range linters {
+		if f.Match(l.name, l.tags) {
+			selected = append(selected, l)
+		}
+	}
+	return selected
+}
@@ -0,0 +1,89 @@
+package golinters
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterValidate(t *testing.T) {
+	tests := []struct {
+		filter Filter
+		err    bool
+	}{
+		{filter: Filter{}},
+		{filter: Filter{Only: []string{"errcheck", "go*", "bugs"}, Exclude: []string{"[a-c]*", "?et"}}},
+		{filter: Filter{Only: []string{"[a-"}}, err: true},
+		{filter: Filter{Exclude: []string{"go*", `x\`}}, err: true},
+	}
+
+	for _, test := range tests {
+		err := test.filter.Validate()
+		if (err != nil) != test.err {
+			t.Errorf("%+v: got error %v, want error: %t", test.filter, err, test.err)
+		}
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter Filter
+		linter string
+		tags   []string
+		want   bool
+	}{
+		{"empty filter", Filter{}, "errcheck", nil, true},
+		{"only name", Filter{Only: []string{"errcheck"}}, "errcheck", nil, true},
+		{"only other name", Filter{Only: []string{"vet"}}, "errcheck", nil, false},
+		{"only glob", Filter{Only: []string{"err*"}}, "errcheck", nil, true},
+		{"only tag", Filter{Only: []string{"bugs"}}, "errcheck", []string{"style", "bugs"}, true},
+		{"only tag glob", Filter{Only: []string{"b?gs"}}, "errcheck", []string{"bugs"}, true},
+		{"only any pattern", Filter{Only: []string{"vet", "bugs"}}, "errcheck", []string{"bugs"}, true},
+		{"exclude name", Filter{Exclude: []string{"errcheck"}}, "errcheck", nil, false},
+		{"exclude glob", Filter{Exclude: []string{"*check"}}, "errcheck", nil, false},
+		{"exclude tag", Filter{Exclude: []string{"bugs"}}, "errcheck", []string{"bugs"}, false},
+		{"exclude other", Filter{Exclude: []string{"vet", "style"}}, "errcheck", []string{"bugs"}, true},
+		{"exclude wins over only", Filter{Only: []string{"errcheck"}, Exclude: []string{"errcheck"}}, "errcheck", nil, false},
+		{"exclude tag wins over only name", Filter{Only: []string{"errcheck"}, Exclude: []string{"bugs"}}, "errcheck", []string{"bugs"}, false},
+		{"invalid pattern matches nothing", Filter{Only: []string{"[a-"}}, "errcheck", nil, false},
+	}
+
+	for _, test := range tests {
+		if got := test.filter.Match(test.linter, test.tags); got != test.want {
+			t.Errorf("%s: got %t, want %t", test.name, got, test.want)
+		}
+	}
+}
+
+func TestFilterResults(t *testing.T) {
+	results := []Result{
+		{Name: "errcheck", Tags: []string{"bugs"}},
+		{Name: "golint", Tags: []string{"style"}},
+		{Name: "gosimple", Tags: []string{"style", "bugs"}},
+		{Name: "vet"},
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"all", Filter{}, []string{"errcheck", "golint", "gosimple", "vet"}},
+		{"only tag", Filter{Only: []string{"bugs"}}, []string{"errcheck", "gosimple"}},
+		{"only glob", Filter{Only: []string{"go*"}}, []string{"golint", "gosimple"}},
+		{"exclude tag", Filter{Exclude: []string{"style"}}, []string{"errcheck", "vet"}},
+		{"only and exclude", Filter{Only: []string{"go*", "vet"}, Exclude: []string{"bugs"}}, []string{"golint", "vet"}},
+		{"same name in both", Filter{Only: []string{"vet"}, Exclude: []string{"vet"}}, nil},
+		{"nothing", Filter{Only: []string{"staticcheck"}}, nil},
+	}
+
+	for _, test := range tests {
+		var got []string
+		for _, r := range test.filter.Results(results) {
+			got = append(got, r.Name)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
@@ -1,11 +1,12 @@
 package gometalinter
 
 import (
+	"context"
 	"errors"
 	"go/ast"
 	"go/token"
 
-	"golang.org/x/tools/go/loader"
+	"github.com/thomasheller/golinters/load"
 )
 
 // GometalinterAST attemts to find the current linter definitions in
@@ -15,24 +16,23 @@ import (
 // to dig through a particular AST manually. Note that there exists
 // ast.Walk if you're OK with depth-first search.
 type GometalinterAST struct {
+	// Loader specifies where and how to load gometalinter. Its
+	// mode is always load.Syntax.
+	Loader load.Config
+
 	defs []string
 }
 
 func (g *GometalinterAST) GetLinterDefinitions() ([]string, error) {
-	args := []string{"github.com/alecthomas/gometalinter"}
-
-	var conf loader.Config
-	if _, err := conf.FromArgs(args, false); err != nil {
-		return nil, err
-	}
+	conf := g.Loader
+	conf.Mode = load.Syntax
 
-	var lprog *loader.Program
-	var err error
-	if lprog, err = conf.Load(); err != nil {
+	prog, err := conf.Load(context.Background(), "github.com/alecthomas/gometalinter")
+	if err != nil {
 		return nil, err
 	}
 
-	found := g.parseProg(lprog)
+	found := g.parseProg(prog)
 
 	if !found {
 		return nil, errors.New("linter definitions not found")
@@ -41,9 +41,9 @@ func (g *GometalinterAST) GetLinterDefinitions() ([]string, error) {
 	return g.defs, nil
 }
 
-func (g *GometalinterAST) parseProg(lprog *loader.Program) bool {
-	for _, pkg := range lprog.InitialPackages() {
-		if g.parseFiles(pkg.Files) {
+func (g *GometalinterAST) parseProg(prog *load.Program) bool {
+	for _, pkg := range prog.Initial {
+		if g.parseFiles(pkg.Syntax) {
 			return true
 		}
 	}
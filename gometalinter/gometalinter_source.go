@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"errors"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/thomasheller/gopath"
@@ -15,6 +16,10 @@ import (
 // returned strings are inaccurate, but this is sufficient for what
 // golinters wants to check.
 type GometalinterSource struct {
+	// Dir is the directory of gometalinter's source code. If
+	// empty, it is looked up in GOPATH.
+	Dir string
+
 	s    *bufio.Scanner
 	defs []string
 }
@@ -22,11 +27,16 @@ type GometalinterSource struct {
 type stateFn func(*GometalinterSource) (stateFn, error)
 
 func (g *GometalinterSource) GetLinterDefinitions() ([]string, error) {
-	path, err := gopath.Join("src/github.com/alecthomas/gometalinter/config.go")
-	if err != nil {
-		return nil, err
+	dir := g.Dir
+	if dir == "" {
+		var err error
+		if dir, err = gopath.Join("src/github.com/alecthomas/gometalinter"); err != nil {
+			return nil, err
+		}
 	}
 
+	path := filepath.Join(dir, "config.go")
+
 	r, err := os.Open(path)
 	if err != nil {
 		return nil, err
@@ -1,31 +1,32 @@
 package gometalinter
 
 import (
+	"context"
 	"errors"
 
-	"golang.org/x/tools/go/loader"
 	"golang.org/x/tools/go/ssa/ssautil"
+
+	"github.com/thomasheller/golinters/load"
 )
 
 type GometalinterSSA struct {
+	// Loader specifies where and how to load gometalinter. Its
+	// mode is always load.Syntax.
+	Loader load.Config
+
 	defs []string
 }
 
 func (g *GometalinterSSA) GetLinterDefinitions() ([]string, error) {
-	args := []string{"github.com/alecthomas/gometalinter"}
-
-	var conf loader.Config
-	if _, err := conf.FromArgs(args, false); err != nil {
-		return nil, err
-	}
+	conf := g.Loader
+	conf.Mode = load.Syntax
 
-	var lprog *loader.Program
-	var err error
-	if lprog, err = conf.Load(); err != nil {
+	prog, err := conf.Load(context.Background(), "github.com/alecthomas/gometalinter")
+	if err != nil {
 		return nil, err
 	}
 
-	found := g.parseSSA(lprog)
+	found := g.parseSSA(prog)
 
 	if !found {
 		return nil, errors.New("linter definitions not found")
@@ -34,8 +35,8 @@ func (g *GometalinterSSA) GetLinterDefinitions() ([]string, error) {
 	return g.defs, nil
 }
 
-func (g *GometalinterSSA) parseSSA(lprog *loader.Program) bool {
-	prog := ssautil.CreateProgram(lprog, 0)
+func (g *GometalinterSSA) parseSSA(lprog *load.Program) bool {
+	prog, _ := ssautil.AllPackages(lprog.Initial, 0)
 	prog.Build()
 
 	for _, pkg := range ssautil.MainPackages(prog.AllPackages()) {
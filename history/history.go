@@ -0,0 +1,176 @@
+// Package history records golinters runs in a local BoltDB file, so
+// linters can be followed over time: when they gained a capability,
+// moved to another repository, got added to gometalinter and so on.
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/thomasheller/golinters"
+)
+
+var (
+	// runsBucket holds the JSON reports, keyed by timestamp.
+	runsBucket = []byte("runs")
+	// revisionsBucket has a bucket per linter, which maps each
+	// analyzed revision to the key of the run it was first
+	// analyzed in.
+	revisionsBucket = []byte("revisions")
+)
+
+// keyFormat formats run timestamps as fixed-width keys, which sort
+// chronologically.
+const keyFormat = "2006-01-02T15:04:05.000000000Z"
+
+// DB is a history database.
+type DB struct {
+	db *bolt.DB
+}
+
+// Open opens the history database in file, creating it if it doesn't
+// exist.
+func Open(file string) (*DB, error) {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return nil, err
+	}
+
+	db, err := bolt.Open(file, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{runsBucket, revisionsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &DB{db}, nil
+}
+
+// Close closes the database.
+func (h *DB) Close() error {
+	return h.db.Close()
+}
+
+func runKey(t time.Time) []byte {
+	return []byte(t.UTC().Format(keyFormat))
+}
+
+// Add records a run. It fails if a run with the same timestamp was
+// recorded before.
+func (h *DB) Add(r *golinters.Report) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	key := runKey(r.Timestamp)
+
+	return h.db.Update(func(tx *bolt.Tx) error {
+		runs := tx.Bucket(runsBucket)
+		if runs.Get(key) != nil {
+			return fmt.Errorf("run of %v already recorded", r.Timestamp)
+		}
+		if err := runs.Put(key, data); err != nil {
+			return err
+		}
+
+		revisions := tx.Bucket(revisionsBucket)
+		for _, res := range r.Results {
+			if res.Revision == "" {
+				continue
+			}
+			b, err := revisions.CreateBucketIfNotExists([]byte(res.Name))
+			if err != nil {
+				return err
+			}
+			if b.Get([]byte(res.Revision)) == nil {
+				if err := b.Put([]byte(res.Revision), key); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Runs returns all recorded runs, oldest first.
+func (h *DB) Runs() ([]*golinters.Report, error) {
+	var reports []*golinters.Report
+
+	err := h.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(runsBucket).ForEach(func(k, v []byte) error {
+			r, err := golinters.ReadJSON(bytes.NewReader(v))
+			if err != nil {
+				return fmt.Errorf("run %s: %v", k, err)
+			}
+			reports = append(reports, r)
+			return nil
+		})
+	})
+
+	return reports, err
+}
+
+// Run returns the run recorded at t, or nil if there is none.
+func (h *DB) Run(t time.Time) (*golinters.Report, error) {
+	var report *golinters.Report
+
+	err := h.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(runsBucket).Get(runKey(t))
+		if v == nil {
+			return nil
+		}
+		var err error
+		report, err = golinters.ReadJSON(bytes.NewReader(v))
+		return err
+	})
+
+	return report, err
+}
+
+// Revision is a revision of a linter and when it was first analyzed.
+type Revision struct {
+	Revision string    `json:"revision"`
+	Time     time.Time `json:"time"`
+}
+
+// Revisions returns all analyzed revisions of a linter, oldest first.
+func (h *DB) Revisions(linter string) ([]Revision, error) {
+	var revs []Revision
+
+	err := h.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(revisionsBucket).Bucket([]byte(linter))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			t, err := time.Parse(keyFormat, string(v))
+			if err != nil {
+				return err
+			}
+			revs = append(revs, Revision{string(k), t})
+			return nil
+		})
+	})
+
+	sort.SliceStable(revs, func(i, j int) bool { return revs[i].Time.Before(revs[j].Time) })
+
+	return revs, err
+}
@@ -0,0 +1,68 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/thomasheller/golinters"
+)
+
+func TestAdd(t *testing.T) {
+	h, err := Open(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+
+	// Runs in quick succession, e.g. from scripts, must get keys of
+	// their own.
+	t0 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	report := func(d time.Duration, rev string) *golinters.Report {
+		r := golinters.NewReport([]golinters.Result{{Name: "a", Revision: rev}})
+		r.Timestamp = t0.Add(d)
+		return r
+	}
+	a := report(0, "r1")
+	b := report(time.Millisecond, "r1")
+	c := report(time.Millisecond+time.Nanosecond, "r2")
+
+	for _, r := range []*golinters.Report{a, b, c} {
+		if err := h.Add(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := h.Add(b); err == nil {
+		t.Errorf("adding a run twice: got no error")
+	}
+
+	runs, err := h.Runs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 3 {
+		t.Fatalf("got %d runs, want 3", len(runs))
+	}
+	for i, want := range []*golinters.Report{a, b, c} {
+		if !runs[i].Timestamp.Equal(want.Timestamp) {
+			t.Errorf("run %d: got timestamp %v, want %v", i, runs[i].Timestamp, want.Timestamp)
+		}
+	}
+
+	run, err := h.Run(b.Timestamp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run == nil || !run.Timestamp.Equal(b.Timestamp) {
+		t.Errorf("Run(%v): got %v", b.Timestamp, run)
+	}
+
+	revs, err := h.Revisions("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revs) != 2 || revs[0].Revision != "r1" || !revs[0].Time.Equal(a.Timestamp) || revs[1].Revision != "r2" || !revs[1].Time.Equal(c.Timestamp) {
+		t.Errorf("got revisions %+v, want r1 at %v and r2 at %v", revs, a.Timestamp, c.Timestamp)
+	}
+}
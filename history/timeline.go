@@ -0,0 +1,253 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/thomasheller/golinters"
+)
+
+// Event is a change of a linter between two runs.
+type Event struct {
+	// Time is when the run that noticed the change was written.
+	Time time.Time `json:"time"`
+	// Revision is the linter's revision analyzed in that run.
+	Revision string               `json:"revision,omitempty"`
+	Kind     golinters.ChangeKind `json:"kind"`
+	// Field is "revision", "maintainer", "url" or the name of a
+	// detector. It is empty for added and removed linters.
+	Field string `json:"field,omitempty"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+	// Text describes the change, e.g. "gained go/ssa".
+	Text string `json:"text"`
+}
+
+// Timeline is the history of a linter.
+type Timeline struct {
+	Linter string  `json:"linter"`
+	Events []Event `json:"events"`
+}
+
+// state is what is known about a linter at some point in time.
+type state struct {
+	present    bool
+	revision   string
+	maintainer string
+	url        string
+	usage      map[string]golinters.Usage
+}
+
+// Timelines returns the timelines of all linters in the given runs,
+// which must be ordered oldest first. Timelines are sorted by linter
+// name. Capabilities that couldn't be detected in a run are compared
+// to the last run in which they could, so failed runs don't show up
+// as changes.
+func Timelines(runs []*golinters.Report) []Timeline {
+	states := make(map[string]*state)
+	timelines := make(map[string]*Timeline)
+
+	for _, run := range runs {
+		seen := make(map[string]bool)
+
+		for _, r := range run.Results {
+			seen[r.Name] = true
+
+			s, ok := states[r.Name]
+			if !ok {
+				s = &state{usage: make(map[string]golinters.Usage)}
+				states[r.Name] = s
+				timelines[r.Name] = &Timeline{Linter: r.Name, Events: []Event{}}
+			}
+
+			t := timelines[r.Name]
+			event := func(kind golinters.ChangeKind, field, old, cur, text string) {
+				t.Events = append(t.Events, Event{
+					Time:     run.Timestamp,
+					Revision: r.Revision,
+					Kind:     kind,
+					Field:    field,
+					Old:      old,
+					New:      cur,
+					Text:     text,
+				})
+			}
+
+			if !s.present {
+				event(golinters.Added, "", "", "", "added")
+				s.present = true
+			}
+
+			if r.Revision != "" {
+				if s.revision != "" && s.revision != r.Revision {
+					event(golinters.Changed, "revision", s.revision, r.Revision,
+						fmt.Sprintf("updated from %s to %s", s.revision, r.Revision))
+				}
+				s.revision = r.Revision
+			}
+
+			if r.Repo != nil {
+				if s.url != "" && s.url != r.Repo.URL {
+					event(golinters.Changed, "url", s.url, r.Repo.URL,
+						fmt.Sprintf("moved from %s to %s", s.url, r.Repo.URL))
+				}
+				if s.maintainer != "" && s.maintainer != r.Repo.Maintainer {
+					event(golinters.Changed, "maintainer", s.maintainer, r.Repo.Maintainer,
+						fmt.Sprintf("maintainer changed from %s to %s", s.maintainer, r.Repo.Maintainer))
+				}
+				s.url, s.maintainer = r.Repo.URL, r.Repo.Maintainer
+			}
+
+			for _, d := range r.Columns {
+				if d.Usage == golinters.Unknown {
+					continue
+				}
+				if old, ok := s.usage[d.Name]; ok && old != d.Usage {
+					event(golinters.Changed, d.Name, old.String(), d.Usage.String(), describe(d, old))
+				}
+				s.usage[d.Name] = d.Usage
+			}
+		}
+
+		for name, s := range states {
+			if s.present && !seen[name] {
+				t := timelines[name]
+				t.Events = append(t.Events, Event{
+					Time: run.Timestamp,
+					Kind: golinters.Removed,
+					Text: "removed from the registry",
+				})
+				s.present = false
+			}
+		}
+	}
+
+	var ts []Timeline
+	for _, t := range timelines {
+		ts = append(ts, *t)
+	}
+
+	sort.Slice(ts, func(i, j int) bool { return ts[i].Linter < ts[j].Linter })
+
+	return ts
+}
+
+// describe describes the change of a detection from usage old.
+func describe(d golinters.Detection, old golinters.Usage) string {
+	gained := old <= golinters.Unused && d.Usage > golinters.Unused
+	lost := old > golinters.Unused && d.Usage <= golinters.Unused
+
+	if d.Group == "Metalinter support" {
+		switch {
+		case gained:
+			return fmt.Sprintf("added to %s", d.Name)
+		case lost:
+			return fmt.Sprintf("removed from %s", d.Name)
+		}
+	}
+
+	switch {
+	case gained:
+		return fmt.Sprintf("gained %s", d.Name)
+	case lost:
+		return fmt.Sprintf("lost %s", d.Name)
+	}
+
+	return fmt.Sprintf("%s usage changed from %s to %s", d.Name, usageText(old), usageText(d.Usage))
+}
+
+func usageText(u golinters.Usage) string {
+	text, _ := u.MarshalText()
+	return string(text)
+}
+
+// WriteText writes the timelines as plain text, one event per line.
+func WriteText(w io.Writer, timelines []Timeline) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	for i, t := range timelines {
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+		fmt.Fprintln(tw, t.Linter)
+		for _, e := range t.Events {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\n", e.Time.Local().Format("2006-01-02 15:04"), e.Revision, e.Text)
+		}
+	}
+
+	return tw.Flush()
+}
+
+// WriteJSON writes the timelines as JSON.
+func WriteJSON(w io.Writer, timelines []Timeline) error {
+	if timelines == nil {
+		timelines = []Timeline{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(timelines)
+}
+
+// WriteHTML writes the timelines as a HTML page.
+func WriteHTML(w io.Writer, timelines []Timeline) error {
+	return htmlTemplate.Execute(w, timelines)
+}
+
+var htmlTemplate = template.Must(template.New("history").Funcs(template.FuncMap{
+	"date": func(t time.Time) string { return t.Local().Format("2006-01-02 15:04") },
+}).Parse(`<!DOCTYPE html>
+<html>
+	<head>
+		<meta http-equiv="Content-Type" content="text/html; charset=utf-8">
+		<style>
+			html, body {
+				font-family: Arial, sans-serif;
+			}
+			tt {
+				font-family: Menlo, monospace;
+			}
+			table, th, td {
+				border: 1px solid #000;
+				border-collapse: collapse;
+			}
+			th, td {
+				padding: .33em;
+			}
+			tr.added td {
+				background-color: #a9e3a0;
+			}
+			tr.removed td {
+				background-color: #e8a0a0;
+			}
+		</style>
+	</head>
+	<body>
+		{{ range . }}<h2 id="{{ .Linter }}">{{ .Linter }}</h2>
+		<table>
+			<thead>
+				<tr>
+					<th>Date</th>
+					<th>Revision</th>
+					<th>Change</th>
+				</tr>
+			</thead>
+			<tbody>
+				{{ range .Events }}<tr class="{{ .Kind }}">
+					<td>{{ date .Time }}</td>
+					<td><tt>{{ .Revision }}</tt></td>
+					<td>{{ .Text }}</td>
+				</tr>
+				{{ end -}}
+			</tbody>
+		</table>
+		{{ else }}<p>No runs recorded.</p>
+		{{ end -}}
+	</body>
+</html>`))
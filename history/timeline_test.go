@@ -0,0 +1,147 @@
+package history
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/thomasheller/golinters"
+	"github.com/thomasheller/golinters/repo"
+)
+
+func TestTimelines(t *testing.T) {
+	t0 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(i int) time.Time { return t0.Add(time.Duration(i) * time.Hour) }
+
+	run := func(i int, results ...golinters.Result) *golinters.Report {
+		return &golinters.Report{Timestamp: at(i), Results: results}
+	}
+	result := func(name, rev, url string, columns ...golinters.Detection) golinters.Result {
+		r := golinters.Result{Name: name, Revision: rev, Columns: columns}
+		if url != "" {
+			r.Repo = &repo.Repository{Maintainer: "m " + url, URL: url}
+		}
+		return r
+	}
+	col := func(group, name string, u golinters.Usage) golinters.Detection {
+		return golinters.Detection{Name: name, Group: group, Usage: u}
+	}
+	ssa := func(u golinters.Usage) golinters.Detection { return col("Packages", "go/ssa", u) }
+	gml := func(u golinters.Usage) golinters.Detection { return col("Metalinter support", "gometalinter", u) }
+
+	tests := []struct {
+		name string
+		runs []*golinters.Report
+		want []Timeline
+	}{
+		{
+			name: "no runs",
+		},
+		{
+			name: "added, sorted by name",
+			runs: []*golinters.Report{
+				run(0, result("b", "r1", ""), result("a", "", "")),
+			},
+			want: []Timeline{
+				{"a", []Event{{Time: at(0), Kind: golinters.Added, Text: "added"}}},
+				{"b", []Event{{Time: at(0), Revision: "r1", Kind: golinters.Added, Text: "added"}}},
+			},
+		},
+		{
+			name: "unchanged",
+			runs: []*golinters.Report{
+				run(0, result("a", "r1", "u1", ssa(golinters.UsedByMain))),
+				run(1, result("a", "r1", "u1", ssa(golinters.UsedByMain))),
+			},
+			want: []Timeline{
+				{"a", []Event{{Time: at(0), Revision: "r1", Kind: golinters.Added, Text: "added"}}},
+			},
+		},
+		{
+			name: "revision, url and maintainer",
+			runs: []*golinters.Report{
+				run(0, result("a", "r1", "u1")),
+				run(1, result("a", "r2", "u2")),
+			},
+			want: []Timeline{
+				{"a", []Event{
+					{Time: at(0), Revision: "r1", Kind: golinters.Added, Text: "added"},
+					{Time: at(1), Revision: "r2", Kind: golinters.Changed, Field: "revision", Old: "r1", New: "r2", Text: "updated from r1 to r2"},
+					{Time: at(1), Revision: "r2", Kind: golinters.Changed, Field: "url", Old: "u1", New: "u2", Text: "moved from u1 to u2"},
+					{Time: at(1), Revision: "r2", Kind: golinters.Changed, Field: "maintainer", Old: "m u1", New: "m u2", Text: "maintainer changed from m u1 to m u2"},
+				}},
+			},
+		},
+		{
+			name: "missing revision and repository aren't changes",
+			runs: []*golinters.Report{
+				run(0, result("a", "r1", "u1")),
+				run(1, result("a", "", "")),
+				run(2, result("a", "r1", "u1")),
+			},
+			want: []Timeline{
+				{"a", []Event{{Time: at(0), Revision: "r1", Kind: golinters.Added, Text: "added"}}},
+			},
+		},
+		{
+			name: "capabilities",
+			runs: []*golinters.Report{
+				run(0, result("a", "", "", ssa(golinters.Unused), gml(golinters.Unused))),
+				run(1, result("a", "", "", ssa(golinters.UsedByDependency), gml(golinters.UsedByMain))),
+				run(2, result("a", "", "", ssa(golinters.UsedByMain), gml(golinters.Unused))),
+				run(3, result("a", "", "", ssa(golinters.Unused), gml(golinters.Unused))),
+			},
+			want: []Timeline{
+				{"a", []Event{
+					{Time: at(0), Kind: golinters.Added, Text: "added"},
+					{Time: at(1), Kind: golinters.Changed, Field: "go/ssa", Old: "N", New: "dep", Text: "gained go/ssa"},
+					{Time: at(1), Kind: golinters.Changed, Field: "gometalinter", Old: "N", New: "Y", Text: "added to gometalinter"},
+					{Time: at(2), Kind: golinters.Changed, Field: "go/ssa", Old: "dep", New: "Y", Text: "go/ssa usage changed from dependency to main"},
+					{Time: at(2), Kind: golinters.Changed, Field: "gometalinter", Old: "Y", New: "N", Text: "removed from gometalinter"},
+					{Time: at(3), Kind: golinters.Changed, Field: "go/ssa", Old: "Y", New: "N", Text: "lost go/ssa"},
+				}},
+			},
+		},
+		{
+			name: "unknown usage is compared to the last known one",
+			runs: []*golinters.Report{
+				run(0, result("a", "", "", ssa(golinters.UsedByMain))),
+				run(1, result("a", "", "", ssa(golinters.Unknown))),
+				run(2, result("a", "", "", ssa(golinters.UsedByMain))),
+				run(3, result("a", "", "", ssa(golinters.Unknown))),
+				run(4, result("a", "", "", ssa(golinters.Unused))),
+			},
+			want: []Timeline{
+				{"a", []Event{
+					{Time: at(0), Kind: golinters.Added, Text: "added"},
+					{Time: at(4), Kind: golinters.Changed, Field: "go/ssa", Old: "Y", New: "N", Text: "lost go/ssa"},
+				}},
+			},
+		},
+		{
+			name: "removed and added again",
+			runs: []*golinters.Report{
+				run(0, result("a", "r1", ""), result("b", "", "")),
+				run(1, result("b", "", "")),
+				run(2, result("b", "", "")),
+				run(3, result("a", "r2", ""), result("b", "", "")),
+			},
+			want: []Timeline{
+				{"a", []Event{
+					{Time: at(0), Revision: "r1", Kind: golinters.Added, Text: "added"},
+					{Time: at(1), Kind: golinters.Removed, Text: "removed from the registry"},
+					{Time: at(3), Revision: "r2", Kind: golinters.Added, Text: "added"},
+					{Time: at(3), Revision: "r2", Kind: golinters.Changed, Field: "revision", Old: "r1", New: "r2", Text: "updated from r1 to r2"},
+				}},
+				{"b", []Event{{Time: at(0), Kind: golinters.Added, Text: "added"}}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		got := Timelines(test.runs)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s:\ngot  %+v\nwant %+v", test.name, got, test.want)
+		}
+	}
+}
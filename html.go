@@ -0,0 +1,298 @@
+package golinters
+
+import (
+	"html/template"
+	"io"
+)
+
+var builtinTemplate = template.Must(template.New("html").Funcs(TemplateFuncs()).Parse(htmlTemplate))
+
+// WriteHTML renders results as a HTML report, using the built-in
+// template.
+func WriteHTML(w io.Writer, results []Result) error {
+	return NewReport(results).WriteHTML(w)
+}
+
+// WriteHTML renders the report as HTML, using the built-in template.
+func (r *Report) WriteHTML(w io.Writer) error {
+	return r.WriteHTMLTemplate(w, builtinTemplate)
+}
+
+// BuiltinTemplate returns the built-in HTML report template. It is
+// executed with TemplateData.
+func BuiltinTemplate() *template.Template {
+	return builtinTemplate
+}
+
+const htmlTemplate = `<!DOCTYPE html>
+<html>
+	<head>
+		<meta http-equiv="Content-Type" content="text/html; charset=utf-8">
+		<style>
+			html, body {
+				font-family: Arial, sans-serif;
+			}
+			tt {
+				font-family: Menlo, monospace;
+			}
+			table, th, td {
+				border: 1px solid #000;
+				border-collapse: collapse;
+			}
+			th, td {
+				padding: .33em;
+			}
+			td.t, td.r, td.d, td.f, td.u {
+				text-align: center;
+			}
+			.t {
+				background-color: #5bd64a;
+			}
+			.r {
+				background-color: #a9e3a0;
+			}
+			.d {
+				background-color: #e6d36e;
+			}
+			.f {
+				background-color: #d64a4a;
+			}
+			.u {
+				background-color: #c8c8c8;
+			}
+			.error {
+				color: #b00;
+			}
+			td.notes, .timestamp, .legend {
+				font-size: small;
+			}
+			td a {
+				color: inherit;
+			}
+			.legend span {
+				padding: 0 .33em;
+			}
+			.controls {
+				margin-bottom: 1em;
+			}
+			.controls label {
+				margin-right: 1em;
+			}
+			th.sortable {
+				cursor: pointer;
+			}
+			th.asc:after {
+				content: " \25B2";
+			}
+			th.desc:after {
+				content: " \25BC";
+			}
+			.hidden {
+				display: none;
+			}
+			#analysis {
+				margin-bottom: 1em;
+			}
+		</style>
+	</head>
+	<body>
+		{{ if .Server }}<form id="analysis" method="post" action="/api/analysis">
+			<button type="submit">Re-analyze</button>
+			<span id="progress"></span>
+		</form>
+		{{ end -}}
+		<form class="controls hidden" id="controls">
+			<label>Filter: <input type="search" id="filter" placeholder="name, maintainer, notes"></label>
+			<label>Show only linters with
+				<select id="only"><option value="">any capability</option></select>
+				<select id="level">
+					<option value="1">used anywhere</option>
+					<option value="2">used in their repository</option>
+					<option value="3">used by their main package</option>
+				</select>
+			</label>
+			<span id="groups">Columns:</span>
+		</form>
+		<table id="linters">
+			<thead>
+				<tr>
+					<th colspan="3">General info</th>
+					{{ range .Groups }}<th colspan="{{ len .Columns }}" data-group="{{ .Name }}">{{ .Name }}</th>
+					{{ end }}<th rowspan="2">Notes</th>
+				</tr>
+				<tr>
+					<th>Name</th>
+					<th>Maintainer</th>
+					<th>Repository URL</th>
+					{{ range $g := .Groups }}{{ range .Columns }}<th data-group="{{ $g.Name }}"><tt>{{ . }}</tt></th>
+					{{ end }}{{ end -}}
+				</tr>
+			</thead>
+			<tbody>
+				{{ range .Results }}<tr>
+					<td>{{ if $.LinterURL }}<a href="{{ call $.LinterURL .Name }}">{{ .Name }}</a>{{ else }}{{ .Name }}{{ end }}</td>
+					{{ if .Repo }}<td>{{ .Repo.Maintainer }}</td>
+					<td><a href="{{ .Repo.URL }}">{{ .Repo.URL }}</a></td>
+					{{ else }}<td class="u" title="{{ .RepoError }}">?</td>
+					<td class="u" title="{{ .RepoError }}">?</td>
+					{{ end }}
+					{{ range .Columns }}{{ cell . }}
+					{{ end }}<td class="notes">{{ .Notes }}{{ range .Errors }}<div class="error">{{ . }}</div>{{ end }}</td>
+				</tr>{{ end }}
+			</tbody>
+		</table>
+		<p class="legend">
+			Input and options:
+			{{ range $i, $u := .Usages }}{{ if $i }},{{ end }}
+			<span class="{{ $u.Class }}">{{ $u }}</span> {{ describe $u }}{{ end }}.
+			Hover over a cell to see where a capability was found, click it to view the source.
+			Click a column header to sort.
+		</p>
+		<p class="timestamp">{{ .Timestamp }}</p>
+		<script>
+			(function() {
+				var table = document.getElementById("linters");
+				var rows = Array.prototype.slice.call(table.tBodies[0].rows);
+				var headers = Array.prototype.slice.call(table.tHead.rows[1].cells);
+				var filter = document.getElementById("filter");
+				var only = document.getElementById("only");
+				var level = document.getElementById("level");
+
+				rows.forEach(function(row, i) { row.setAttribute("data-index", i); });
+
+				// value returns the sort key of a cell: the usage for
+				// capability columns, the lowercase text otherwise.
+				function value(row, col) {
+					var cell = row.cells[col];
+					var v = cell.getAttribute("data-value");
+					return v === null ? cell.textContent.trim().toLowerCase() : parseInt(v, 10);
+				}
+
+				// sorting
+				var sortCol = -1, sortDir = 1;
+				headers.forEach(function(th) {
+					th.classList.add("sortable");
+					th.title = "Click to sort";
+					th.addEventListener("click", function() {
+						var col = th.cellIndex;
+						if (col === sortCol) {
+							sortDir = -sortDir;
+						} else {
+							// capabilities: most direct usage first
+							sortDir = th.hasAttribute("data-group") ? -1 : 1;
+						}
+						sortCol = col;
+						headers.forEach(function(h) { h.classList.remove("asc", "desc"); });
+						th.classList.add(sortDir > 0 ? "asc" : "desc");
+						rows.sort(function(a, b) {
+							var x = value(a, col), y = value(b, col);
+							if (x < y) return -sortDir;
+							if (x > y) return sortDir;
+							return a.getAttribute("data-index") - b.getAttribute("data-index");
+						});
+						rows.forEach(function(row) { table.tBodies[0].appendChild(row); });
+					});
+				});
+
+				// filtering
+				headers.forEach(function(th) {
+					if (th.hasAttribute("data-group")) {
+						var opt = document.createElement("option");
+						opt.value = th.cellIndex;
+						opt.textContent = th.textContent;
+						only.appendChild(opt);
+					}
+				});
+
+				function apply() {
+					var text = filter.value.trim().toLowerCase();
+					var col = only.value === "" ? -1 : parseInt(only.value, 10);
+					var min = parseInt(level.value, 10);
+					rows.forEach(function(row) {
+						var show = row.textContent.toLowerCase().indexOf(text) >= 0;
+						if (show && col >= 0) {
+							show = value(row, col) >= min;
+						}
+						row.classList.toggle("hidden", !show);
+					});
+				}
+
+				[filter, only, level].forEach(function(el) {
+					el.addEventListener("input", apply);
+					el.addEventListener("change", apply);
+				});
+
+				// column groups
+				var groups = document.getElementById("groups");
+				Array.prototype.forEach.call(table.tHead.rows[0].cells, function(th) {
+					var name = th.getAttribute("data-group");
+					if (name === null) {
+						return;
+					}
+					var label = document.createElement("label");
+					var box = document.createElement("input");
+					box.type = "checkbox";
+					box.checked = true;
+					box.addEventListener("change", function() {
+						Array.prototype.forEach.call(table.querySelectorAll("[data-group]"), function(el) {
+							if (el.getAttribute("data-group") === name) {
+								el.classList.toggle("hidden", !box.checked);
+							}
+						});
+					});
+					label.appendChild(box);
+					label.appendChild(document.createTextNode(" " + name));
+					groups.appendChild(document.createTextNode(" "));
+					groups.appendChild(label);
+				});
+
+				var controls = document.getElementById("controls");
+				controls.addEventListener("submit", function(e) { e.preventDefault(); });
+				controls.classList.remove("hidden");
+			})();
+		</script>
+		{{ if .Server }}<script>
+			(function() {
+				var form = document.getElementById("analysis");
+				var button = form.querySelector("button");
+				var progress = document.getElementById("progress");
+				var running = false;
+
+				function show(s) {
+					button.disabled = s.running;
+					if (s.running) {
+						var text = s.stage === "fetch" ? "Fetching" : "Analyzing";
+						progress.textContent = text + " " + (s.linter || "") + " (" + s.done + "/" + s.total + ")";
+					} else if (s.error) {
+						progress.textContent = "Analysis failed: " + s.error;
+					} else {
+						progress.textContent = "";
+					}
+					if (running && !s.running && !s.error) {
+						location.reload();
+					}
+					running = s.running;
+					if (running) {
+						setTimeout(poll, 1000);
+					}
+				}
+
+				function poll() {
+					fetch("/api/analysis").then(function(r) { return r.json(); }).then(show);
+				}
+
+				form.addEventListener("submit", function(e) {
+					e.preventDefault();
+					fetch("/api/analysis", {
+						method: "POST",
+						headers: {"Content-Type": "application/json"},
+						body: "{}"
+					}).then(function(r) { return r.json(); }).then(show);
+				});
+
+				poll();
+			})();
+		</script>
+		{{ end -}}
+	</body>
+</html>`
@@ -0,0 +1,65 @@
+package golinters
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// JSONVersion is the version of the JSON format written by WriteJSON.
+// It is increased whenever fields are removed or change their
+// meaning; adding fields doesn't change the version. The format is
+// described by golinters.schema.json.
+const JSONVersion = 1
+
+// Report is the JSON document written by WriteJSON.
+type Report struct {
+	// Version is JSONVersion at the time the report was written.
+	Version int `json:"version"`
+	// Timestamp is when the report was written.
+	Timestamp time.Time `json:"timestamp"`
+	Results   []Result  `json:"results"`
+}
+
+// NewReport returns a report of results, written now.
+func NewReport(results []Result) *Report {
+	if results == nil {
+		results = []Result{}
+	}
+
+	return &Report{
+		Version:   JSONVersion,
+		Timestamp: time.Now().UTC(),
+		Results:   results,
+	}
+}
+
+// WriteJSON writes results as a JSON report.
+func WriteJSON(w io.Writer, results []Result) error {
+	return NewReport(results).WriteJSON(w)
+}
+
+// WriteJSON writes the report as JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(r)
+}
+
+// ReadJSON reads a JSON report written by WriteJSON. Reports of other
+// versions are rejected.
+func ReadJSON(r io.Reader) (*Report, error) {
+	var report Report
+
+	if err := json.NewDecoder(r).Decode(&report); err != nil {
+		return nil, err
+	}
+
+	if report.Version != JSONVersion {
+		return nil, fmt.Errorf("unsupported report version %d (want %d)", report.Version, JSONVersion)
+	}
+
+	return &report, nil
+}
@@ -5,36 +5,38 @@ type linter struct {
 	cmd     string
 	path    string
 	comment string
+	// tags categorize the linter, see Filter.
+	tags []string
 }
 
 func list() []linter {
 	return []linter{
-		{"aligncheck", "aligncheck", "github.com/opennota/check/cmd/aligncheck", ""},
-		{"deadcode", "deadcode", "github.com/tsenart/deadcode", ""},
-		{"dupl", "dupl", "github.com/mibk/dupl", ""},
-		{"errcheck", "errcheck", "github.com/kisielk/errcheck", ""},
-		{"gas", "gas", "github.com/GoASTScanner/gas", ""},
-		{"goconst", "goconst", "github.com/jgautheron/goconst/cmd/goconst", ""},
-		{"gocyclo", "gocyclo", "github.com/fzipp/gocyclo", "gometalinter uses a fork: github.com/alecthomas/gocyclo"},
-		{"gofmt", "gofmt -l -s", "github.com/golang/go/src/cmd/gofmt", ""},
-		{"goimports", "goimports", "golang.org/x/tools/cmd/goimports", ""},
-		{"golint", "golint", "github.com/golang/lint/golint", ""},
-		{"gosimple", "gosimple", "honnef.co/go/tools/cmd/gosimple", ""},
-		{"gotype", "gotype", "golang.org/x/tools/cmd/gotype", ""},
-		{"ineffassign", "ineffassign", "github.com/gordonklaus/ineffassign", ""},
-		{"interfacer", "interfacer", "github.com/mvdan/interfacer/cmd/interfacer", ""},
-		{"lll", "lll", "github.com/walle/lll/cmd/lll", ""},
-		{"misspell", "misspell", "github.com/client9/misspell/cmd/misspell", ""},
-		{"safesql", "safesql", "github.com/stripe/safesql", ""},
-		{"staticcheck", "staticcheck", "honnef.co/go/tools/cmd/staticcheck", ""},
-		{"structcheck", "structcheck", "github.com/opennota/check/cmd/structcheck", ""},
+		{"aligncheck", "aligncheck", "github.com/opennota/check/cmd/aligncheck", "", []string{"performance"}},
+		{"deadcode", "deadcode", "github.com/tsenart/deadcode", "", []string{"unused"}},
+		{"dupl", "dupl", "github.com/mibk/dupl", "", []string{"style"}},
+		{"errcheck", "errcheck", "github.com/kisielk/errcheck", "", []string{"bugs"}},
+		{"gas", "gas", "github.com/GoASTScanner/gas", "", []string{"security"}},
+		{"goconst", "goconst", "github.com/jgautheron/goconst/cmd/goconst", "", []string{"style"}},
+		{"gocyclo", "gocyclo", "github.com/fzipp/gocyclo", "gometalinter uses a fork: github.com/alecthomas/gocyclo", []string{"complexity"}},
+		{"gofmt", "gofmt -l -s", "github.com/golang/go/src/cmd/gofmt", "", []string{"format"}},
+		{"goimports", "goimports", "golang.org/x/tools/cmd/goimports", "", []string{"format"}},
+		{"golint", "golint", "github.com/golang/lint/golint", "", []string{"style"}},
+		{"gosimple", "gosimple", "honnef.co/go/tools/cmd/gosimple", "", []string{"style"}},
+		{"gotype", "gotype", "golang.org/x/tools/cmd/gotype", "", []string{"bugs"}},
+		{"ineffassign", "ineffassign", "github.com/gordonklaus/ineffassign", "", []string{"unused"}},
+		{"interfacer", "interfacer", "github.com/mvdan/interfacer/cmd/interfacer", "", []string{"style"}},
+		{"lll", "lll", "github.com/walle/lll/cmd/lll", "", []string{"style"}},
+		{"misspell", "misspell", "github.com/client9/misspell/cmd/misspell", "", []string{"style"}},
+		{"safesql", "safesql", "github.com/stripe/safesql", "", []string{"security"}},
+		{"staticcheck", "staticcheck", "honnef.co/go/tools/cmd/staticcheck", "", []string{"bugs"}},
+		{"structcheck", "structcheck", "github.com/opennota/check/cmd/structcheck", "", []string{"unused"}},
 		// {"test", "go test {path}:^--- FAIL:", "github.com/golang/go/src/cmd/go/internal/test", ""}, // TODO
 		// {"testify", "go test {path}:Location:", "github.com/golang/go/src/cmd/go/internal/test", "essentially the same as test, gometalinter parses the output differently"}, // TODO
-		{"unconvert", "unconvert", "github.com/mdempsky/unconvert", ""},
-		{"unparam", "unparam", "github.com/mvdan/unparam", ""},
-		{"unused", "unused", "honnef.co/go/tools/cmd/unused", ""},
-		{"varcheck", "varcheck", "github.com/opennota/check/cmd/varcheck", ""},
-		{"vet", "go tool vet {path}", "github.com/golang/go/src/cmd/vet", ""}, // include "{path}" so it's different from "--shadow"
-		{"vetshadow", "go tool vet --shadow", "github.com/golang/go/src/cmd/vet", "same linter as vet, just run with --shadow"},
+		{"unconvert", "unconvert", "github.com/mdempsky/unconvert", "", []string{"style"}},
+		{"unparam", "unparam", "github.com/mvdan/unparam", "", []string{"unused"}},
+		{"unused", "unused", "honnef.co/go/tools/cmd/unused", "", []string{"unused"}},
+		{"varcheck", "varcheck", "github.com/opennota/check/cmd/varcheck", "", []string{"unused"}},
+		{"vet", "go tool vet {path}", "github.com/golang/go/src/cmd/vet", "", []string{"bugs"}}, // include "{path}" so it's different from "--shadow"
+		{"vetshadow", "go tool vet --shadow", "github.com/golang/go/src/cmd/vet", "same linter as vet, just run with --shadow", []string{"bugs"}},
 	}
 }
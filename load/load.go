@@ -0,0 +1,106 @@
+// Package load loads Go packages along with all their dependencies.
+// It is a thin layer over golang.org/x/tools/go/packages that gives
+// golinters the whole-program view it used to get from go/loader,
+// and works both in GOPATH and module mode.
+package load
+
+import (
+	"context"
+	"fmt"
+	"go/token"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+const (
+	// Imports loads just enough to know the import graph of a
+	// program. This is much faster than loading syntax and types.
+	Imports = packages.NeedName | packages.NeedFiles | packages.NeedImports |
+		packages.NeedDeps | packages.NeedModule
+
+	// Syntax additionally parses and type-checks every package of
+	// the program, like go/loader used to do.
+	Syntax = Imports | packages.NeedSyntax | packages.NeedTypes |
+		packages.NeedTypesInfo | packages.NeedTypesSizes
+)
+
+// Config describes how to load packages. The zero value loads the
+// import graph in the current directory and environment.
+type Config struct {
+	// Mode is Imports or Syntax. Zero means Imports.
+	Mode packages.LoadMode
+	// Dir is the directory to run the go command in.
+	Dir string
+	// Env is the environment of the go command. If nil, the
+	// current environment is used.
+	Env []string
+}
+
+// Program is a set of loaded packages and their dependencies.
+type Program struct {
+	Fset *token.FileSet
+	// Initial are the packages that were requested.
+	Initial []*packages.Package
+}
+
+// Load loads the packages matching the given patterns along with all
+// of their dependencies. Like go/loader, it fails if any package of
+// the program could not be loaded or type-checked.
+func (c Config) Load(ctx context.Context, patterns ...string) (*Program, error) {
+	mode := c.Mode
+	if mode == 0 {
+		mode = Imports
+	}
+
+	fset := token.NewFileSet()
+
+	conf := &packages.Config{
+		Context: ctx,
+		Mode:    mode,
+		Dir:     c.Dir,
+		Env:     c.Env,
+		Fset:    fset,
+	}
+
+	initial, err := packages.Load(conf, patterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(initial) == 0 {
+		return nil, fmt.Errorf("no packages found for %s", strings.Join(patterns, " "))
+	}
+
+	var errs []string
+	packages.Visit(initial, nil, func(p *packages.Package) {
+		for _, e := range p.Errors {
+			errs = append(errs, e.Error())
+		}
+	})
+
+	if len(errs) > 0 {
+		if len(errs) > 10 {
+			errs = append(errs[:10], fmt.Sprintf("(and %d more errors)", len(errs)-10))
+		}
+		return nil, fmt.Errorf("couldn't load %s:\n%s", strings.Join(patterns, " "), strings.Join(errs, "\n"))
+	}
+
+	return &Program{Fset: fset, Initial: initial}, nil
+}
+
+// AllPackages returns the initial packages and all their transitive
+// dependencies, sorted by import path.
+func (p *Program) AllPackages() []*packages.Package {
+	var all []*packages.Package
+	packages.Visit(p.Initial, nil, func(pkg *packages.Package) {
+		all = append(all, pkg)
+	})
+
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].PkgPath < all[j].PkgPath
+	})
+
+	return all
+}
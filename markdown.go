@@ -0,0 +1,82 @@
+package golinters
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteMarkdown writes results as a GitHub-flavored Markdown table.
+// Markdown tables can't span columns, so the group name is put above
+// the first column of each group.
+func WriteMarkdown(w io.Writer, results []Result) error {
+	bw := bufio.NewWriter(w)
+
+	header := []string{"Name", "Maintainer", "Repository"}
+	for _, g := range columnGroups(results) {
+		for i, c := range g.Columns {
+			cell := "<br>`" + mdEscape(c) + "`"
+			if i == 0 {
+				cell = "**" + mdEscape(g.Name) + "**" + cell
+			}
+			header = append(header, cell)
+		}
+	}
+	header = append(header, "Notes")
+
+	mdRow(bw, header)
+
+	sep := make([]string, len(header))
+	for i := range sep {
+		sep[i] = "---"
+		if i >= 3 && i < len(header)-1 {
+			sep[i] = ":---:"
+		}
+	}
+	mdRow(bw, sep)
+
+	for _, r := range results {
+		row := []string{mdEscape(r.Name)}
+
+		if r.Repo != nil {
+			row = append(row, mdEscape(r.Repo.Maintainer), fmt.Sprintf("[%s](%s)", mdEscape(r.Repo.URL), r.Repo.URL))
+		} else {
+			row = append(row, "?", "?")
+		}
+
+		for _, d := range r.Columns {
+			if u := d.URL(); u != "" {
+				row = append(row, fmt.Sprintf("[%s](%s)", d.Usage, u))
+			} else {
+				row = append(row, d.Usage.String())
+			}
+		}
+
+		var notes []string
+		if r.Notes != "" {
+			notes = append(notes, mdEscape(r.Notes))
+		}
+		for _, e := range r.Errors {
+			notes = append(notes, "**Error:** "+mdEscape(e))
+		}
+		row = append(row, strings.Join(notes, " "))
+
+		mdRow(bw, row)
+	}
+
+	fmt.Fprintln(bw)
+	fmt.Fprintln(bw, "Y: used by the linter's main package, repo: used by another package in the linter's repository, dep: only used by third-party dependencies, N: not used, ?: unknown.")
+
+	return bw.Flush()
+}
+
+func mdRow(w io.Writer, cells []string) {
+	fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+}
+
+// mdEscape makes s safe for use in a table cell.
+func mdEscape(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	return strings.NewReplacer("|", `\|`, "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,192 @@
+package golinters
+
+import (
+	"html/template"
+	"io"
+	"sort"
+)
+
+// LinterData is passed to the template of a linter's detail page.
+type LinterData struct {
+	Result
+	// Index is the URL of the report the page belongs to.
+	Index string
+	// LinterURL returns the URL of another linter's page.
+	LinterURL func(name string) string
+	// Prev and Next are the names of the neighboring linters in
+	// the report, if any.
+	Prev, Next string
+	// ImportList holds Imports, most direct first.
+	ImportList []Import
+}
+
+// Import is a package imported by a linter.
+type Import struct {
+	Path  string
+	Usage Usage
+	// Linter is the name of the linter whose main package this
+	// is, if any.
+	Linter string
+}
+
+// NewLinterData returns the data of the page of results[i]. Pages
+// link to each other with linterURL, and to the report at index.
+func NewLinterData(results []Result, i int, index string, linterURL func(name string) string) LinterData {
+	d := LinterData{
+		Result:    results[i],
+		Index:     index,
+		LinterURL: linterURL,
+	}
+
+	if i > 0 {
+		d.Prev = results[i-1].Name
+	}
+	if i < len(results)-1 {
+		d.Next = results[i+1].Name
+	}
+
+	linters := make(map[string]string)
+	for _, r := range results {
+		linters[r.Path] = r.Name
+	}
+
+	for path, u := range d.Imports {
+		d.ImportList = append(d.ImportList, Import{Path: path, Usage: u, Linter: linters[path]})
+	}
+
+	sort.Slice(d.ImportList, func(i, j int) bool {
+		a, b := d.ImportList[i], d.ImportList[j]
+		if a.Usage != b.Usage {
+			return a.Usage > b.Usage
+		}
+		return a.Path < b.Path
+	})
+
+	return d
+}
+
+var linterTemplate = template.Must(template.New("linter").Funcs(TemplateFuncs()).Parse(linterHTML))
+
+// WriteLinterHTML writes a HTML page with everything known about a
+// single linter: its metadata, notes and errors, all detections with
+// their evidence, and all imported packages.
+func WriteLinterHTML(w io.Writer, data LinterData) error {
+	return linterTemplate.Execute(w, data)
+}
+
+const linterHTML = `<!DOCTYPE html>
+<html>
+	<head>
+		<meta http-equiv="Content-Type" content="text/html; charset=utf-8">
+		<title>{{ .Name }}</title>
+		<style>
+			html, body {
+				font-family: Arial, sans-serif;
+			}
+			tt {
+				font-family: Menlo, monospace;
+			}
+			table, th, td {
+				border: 1px solid #000;
+				border-collapse: collapse;
+			}
+			th, td {
+				padding: .33em;
+				vertical-align: top;
+			}
+			th {
+				text-align: left;
+			}
+			td.t, td.r, td.d, td.f, td.u {
+				text-align: center;
+			}
+			.t {
+				background-color: #5bd64a;
+			}
+			.r {
+				background-color: #a9e3a0;
+			}
+			.d {
+				background-color: #e6d36e;
+			}
+			.f {
+				background-color: #d64a4a;
+			}
+			.u {
+				background-color: #c8c8c8;
+			}
+			.error {
+				color: #b00;
+			}
+			td a {
+				color: inherit;
+			}
+			ul {
+				margin: 0;
+				padding-left: 1.2em;
+			}
+			.nav {
+				font-size: small;
+			}
+		</style>
+	</head>
+	<body>
+		<p class="nav">
+			<a href="{{ .Index }}">All linters</a>
+			{{ with .Prev }}| <a href="{{ call $.LinterURL . }}">&larr; {{ . }}</a>{{ end }}
+			{{ with .Next }}| <a href="{{ call $.LinterURL . }}">{{ . }} &rarr;</a>{{ end }}
+		</p>
+		<h1>{{ .Name }}</h1>
+		<table>
+			<tr><th>Command</th><td><tt>{{ .Cmd }}</tt></td></tr>
+			<tr><th>Package</th><td><tt>{{ .Path }}</tt></td></tr>
+			<tr><th>Version</th><td>{{ with .Version }}<tt>{{ . }}</tt>{{ else }}?{{ end }}{{ with .Revision }} (<tt>{{ . }}</tt>){{ end }}</td></tr>
+			{{ if .Repo }}<tr><th>Maintainer</th><td>{{ .Repo.Maintainer }}</td></tr>
+			<tr><th>Repository</th><td><a href="{{ .Repo.URL }}">{{ .Repo.URL }}</a></td></tr>
+			{{ else }}<tr><th>Repository</th><td class="error">{{ .RepoError }}</td></tr>
+			{{ end }}{{ range (column .Result "gometalinter").Evidence }}{{ with .Text }}<tr><th>gometalinter definition</th><td><tt>{{ . }}</tt></td></tr>
+			{{ end }}{{ end }}{{ with .Notes }}<tr><th>Notes</th><td>{{ . }}</td></tr>
+			{{ end }}{{ with .Errors }}<tr><th>Errors</th><td>{{ range . }}<div class="error">{{ . }}</div>{{ end }}</td></tr>
+			{{ end -}}
+		</table>
+		<h2>Capabilities</h2>
+		<table>
+			<thead>
+				<tr>
+					<th>Group</th>
+					<th>Detector</th>
+					<th>Usage</th>
+					<th>Evidence</th>
+				</tr>
+			</thead>
+			<tbody>
+				{{ range .Columns }}<tr>
+					<td>{{ .Group }}</td>
+					<td><tt>{{ .Name }}</tt></td>
+					{{ cell . }}
+					<td>{{ if known . }}{{ with .Evidence }}<ul>{{ range . }}
+						<li>{{ if .URL }}<a href="{{ .URL }}">{{ .String }}</a>{{ else }}{{ .String }}{{ end }}</li>{{ end }}
+					</ul>{{ end }}{{ else }}<span class="error">{{ .Reason }}</span>{{ end }}</td>
+				</tr>
+				{{ end -}}
+			</tbody>
+		</table>
+		{{ with .ImportList }}<h2>Imports</h2>
+		<table>
+			<thead>
+				<tr>
+					<th>Package</th>
+					<th>Usage</th>
+				</tr>
+			</thead>
+			<tbody>
+				{{ range . }}<tr>
+					<td><tt>{{ .Path }}</tt>{{ with .Linter }} (<a href="{{ call $.LinterURL . }}">{{ . }}</a>){{ end }}</td>
+					<td class="{{ .Usage.Class }}">{{ describe .Usage }}</td>
+				</tr>
+				{{ end -}}
+			</tbody>
+		</table>
+		{{ end -}}
+	</body>
+</html>`
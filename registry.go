@@ -0,0 +1,484 @@
+package golinters
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// A registry file lists the linters golinters should know about. It
+// may be written in YAML, TOML or JSON, chosen by file extension:
+//
+//	linters:
+//	  - name: errcheck
+//	    cmd: errcheck
+//	    path: github.com/kisielk/errcheck
+//	    comment: ""
+//	    tags: [bugs]
+//
+// The equivalent TOML uses one [[linters]] table per linter, the
+// equivalent JSON an object with a "linters" array.
+
+// linterEntry is a single linter as read from a registry file, along
+// with the line it was defined on.
+type linterEntry struct {
+	line   int
+	fields map[string]interface{}
+}
+
+// registryFields maps the keys allowed in a registry entry to the
+// linter fields they set, which are either *string or *[]string. Add
+// new keys here.
+func registryFields(l *linter) map[string]interface{} {
+	return map[string]interface{}{
+		"name":    &l.name,
+		"cmd":     &l.cmd,
+		"path":    &l.path,
+		"comment": &l.comment,
+		"tags":    &l.tags,
+	}
+}
+
+// registryError collects all problems found while reading a registry
+// file, so that they can be fixed in one go.
+type registryError struct {
+	file     string
+	problems []registryProblem
+}
+
+type registryProblem struct {
+	line int
+	msg  string
+}
+
+func (e *registryError) add(line int, format string, args ...interface{}) {
+	e.problems = append(e.problems, registryProblem{line, fmt.Sprintf(format, args...)})
+}
+
+func (e *registryError) Error() string {
+	var lines []string
+	for _, p := range e.problems {
+		if p.line > 0 {
+			lines = append(lines, fmt.Sprintf("%s:%d: %s", e.file, p.line, p.msg))
+		} else {
+			lines = append(lines, fmt.Sprintf("%s: %s", e.file, p.msg))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// loadLinters returns the linters defined in the given registry file.
+// If no file is given, the built-in list is returned.
+func loadLinters(file string) ([]linter, error) {
+	if file == "" {
+		return list(), nil
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseRegistry(file, data)
+}
+
+// parseRegistry decodes and validates a registry file. The format is
+// determined by the file extension.
+func parseRegistry(file string, data []byte) ([]linter, error) {
+	regErr := &registryError{file: file}
+
+	var entries []linterEntry
+	var err error
+
+	switch ext := strings.ToLower(filepath.Ext(file)); ext {
+	case ".yaml", ".yml":
+		entries, err = decodeYAML(data, regErr)
+	case ".toml":
+		entries, err = decodeTOML(data, regErr)
+	case ".json":
+		entries, err = decodeJSON(data, regErr)
+	default:
+		return nil, fmt.Errorf("%s: unknown registry format %q (use .yaml, .toml or .json)", file, ext)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	linters := validateEntries(entries, regErr)
+
+	if len(regErr.problems) > 0 {
+		return nil, regErr
+	}
+
+	return linters, nil
+}
+
+// validateEntries converts registry entries to linters and reports
+// missing, unknown or duplicate fields.
+func validateEntries(entries []linterEntry, regErr *registryError) []linter {
+	if len(entries) == 0 && len(regErr.problems) == 0 {
+		regErr.add(0, "no linters defined")
+	}
+
+	var linters []linter
+	seen := make(map[string]int)
+
+	for _, e := range entries {
+		var l linter
+		fields := registryFields(&l)
+		valid := true
+
+		keys := make([]string, 0, len(e.fields))
+		for k := range e.fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			dst, ok := fields[k]
+			if !ok {
+				regErr.add(e.line, "unknown field %q", k)
+				valid = false
+				continue
+			}
+			switch dst := dst.(type) {
+			case *string:
+				s, ok := e.fields[k].(string)
+				if !ok {
+					regErr.add(e.line, "field %q must be a string", k)
+					valid = false
+					continue
+				}
+				*dst = strings.TrimSpace(s)
+			case *[]string:
+				list, ok := stringList(e.fields[k])
+				if !ok {
+					regErr.add(e.line, "field %q must be a list of strings", k)
+					valid = false
+					continue
+				}
+				*dst = list
+			}
+		}
+
+		if _, ok := e.fields["name"]; !ok && l.name == "" {
+			regErr.add(e.line, "missing name")
+			valid = false
+		} else if l.name == "" {
+			regErr.add(e.line, "empty name")
+			valid = false
+		}
+		if _, ok := e.fields["path"]; !ok && l.path == "" {
+			regErr.add(e.line, "missing path")
+			valid = false
+		} else if l.path == "" {
+			regErr.add(e.line, "empty path")
+			valid = false
+		} else if !validPath(l.path) {
+			regErr.add(e.line, "malformed path %q", l.path)
+			valid = false
+		}
+		if l.cmd == "" {
+			l.cmd = l.name
+		}
+
+		if l.name != "" {
+			if first, ok := seen[l.name]; ok {
+				regErr.add(e.line, "duplicate linter %q (first defined on line %d)", l.name, first)
+				valid = false
+			} else {
+				seen[l.name] = e.line
+			}
+		}
+
+		if valid {
+			linters = append(linters, l)
+		}
+	}
+
+	return linters
+}
+
+// validPath reports whether path looks like an import path.
+func validPath(path string) bool {
+	return !strings.ContainsAny(path, " \t") && !strings.HasPrefix(path, "/") && !strings.HasSuffix(path, "/")
+}
+
+// Linter is a linter that isn't in the registry, e.g. given on the
+// command line, see Options.Add.
+type Linter struct {
+	Name string
+	// Cmd is the command line that runs the linter. If empty, the
+	// name is used.
+	Cmd string
+	// Path is the import path of the linter's main package.
+	Path string
+}
+
+func (l Linter) linter() linter {
+	cmd := l.Cmd
+	if cmd == "" {
+		cmd = l.Name
+	}
+	return linter{name: l.Name, cmd: cmd, path: l.Path}
+}
+
+// ParseLinter parses a linter given as "name=path", or as just the
+// path, in which case the name is the last element of the path.
+func ParseLinter(s string) (Linter, error) {
+	name, path := "", s
+	if i := strings.Index(s, "="); i >= 0 {
+		name, path = strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1:])
+	}
+
+	if path == "" || !validPath(path) {
+		return Linter{}, fmt.Errorf("malformed linter %q: want name=path", s)
+	}
+
+	if name == "" {
+		name = path[strings.LastIndex(path, "/")+1:]
+	}
+
+	return Linter{Name: name, Path: path}, nil
+}
+
+// stringList converts a decoded list of strings.
+func stringList(v interface{}) ([]string, bool) {
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return nil, false
+		}
+		list = append(list, strings.TrimSpace(s))
+	}
+	return list, true
+}
+
+// decodeYAML reads registry entries from YAML. Line numbers come
+// from the YAML node tree.
+func decodeYAML(data []byte, regErr *registryError) ([]linterEntry, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %v", regErr.file, err)
+	}
+
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		regErr.add(root.Line, "expected a mapping with a \"linters\" key")
+		return nil, nil
+	}
+
+	var seq *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "linters" {
+			seq = root.Content[i+1]
+		} else {
+			regErr.add(root.Content[i].Line, "unknown key %q", root.Content[i].Value)
+		}
+	}
+
+	if seq == nil {
+		return nil, nil
+	}
+	if seq.Kind != yaml.SequenceNode {
+		regErr.add(seq.Line, "\"linters\" must be a list")
+		return nil, nil
+	}
+
+	var entries []linterEntry
+	for _, item := range seq.Content {
+		var fields map[string]interface{}
+		if err := item.Decode(&fields); err != nil {
+			regErr.add(item.Line, "malformed entry: %v", err)
+			continue
+		}
+		entries = append(entries, linterEntry{item.Line, fields})
+	}
+
+	return entries, nil
+}
+
+// decodeJSON reads registry entries from JSON. The token stream is
+// walked by hand to keep track of the offset (and line) of each entry.
+func decodeJSON(data []byte, regErr *registryError) ([]linterEntry, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	lineAt := func(offset int64) int {
+		// skip whitespace and separators up to the next value
+		for offset < int64(len(data)) && strings.IndexByte(" \t\r\n,:", data[offset]) >= 0 {
+			offset++
+		}
+		return bytes.Count(data[:offset], []byte("\n")) + 1
+	}
+
+	syntaxErr := func(err error) error {
+		if se, ok := err.(*json.SyntaxError); ok {
+			// Offset is just past the offending byte.
+			offset := se.Offset - 1
+			if offset < 0 {
+				offset = 0
+			}
+			return fmt.Errorf("%s:%d: %v", regErr.file, bytes.Count(data[:offset], []byte("\n"))+1, err)
+		}
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return fmt.Errorf("%s: %v", regErr.file, err)
+	}
+
+	expect := func(want json.Delim) error {
+		offset := dec.InputOffset()
+		tok, err := dec.Token()
+		if err != nil {
+			return syntaxErr(err)
+		}
+		if tok != want {
+			return fmt.Errorf("%s:%d: expected %q", regErr.file, lineAt(offset), want)
+		}
+		return nil
+	}
+
+	if err := expect('{'); err != nil {
+		return nil, err
+	}
+
+	var entries []linterEntry
+	for dec.More() {
+		offset := dec.InputOffset()
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, syntaxErr(err)
+		}
+
+		if tok != "linters" {
+			regErr.add(lineAt(offset), "unknown key %q", tok)
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return nil, syntaxErr(err)
+			}
+			continue
+		}
+
+		if err := expect('['); err != nil {
+			return nil, err
+		}
+
+		for dec.More() {
+			line := lineAt(dec.InputOffset())
+			var fields map[string]interface{}
+			if err := dec.Decode(&fields); err != nil {
+				if _, ok := err.(*json.SyntaxError); ok {
+					return nil, syntaxErr(err)
+				}
+				regErr.add(line, "malformed entry: %v", err)
+				continue
+			}
+			entries = append(entries, linterEntry{line, fields})
+		}
+
+		if err := expect(']'); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := expect('}'); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// decodeTOML reads registry entries from TOML. The decoder doesn't
+// report positions of values, so entries are matched up with the
+// lines of their [[linters]] headers.
+func decodeTOML(data []byte, regErr *registryError) ([]linterEntry, error) {
+	var reg struct {
+		Linters []map[string]interface{} `toml:"linters"`
+	}
+
+	md, err := toml.Decode(string(data), &reg)
+	if err != nil {
+		var perr toml.ParseError
+		if errors.As(err, &perr) {
+			return nil, fmt.Errorf("%s:%d: %s", regErr.file, perr.Position.Line, perr.Message)
+		}
+		return nil, fmt.Errorf("%s: %v", regErr.file, err)
+	}
+
+	for _, key := range md.Undecoded() {
+		if len(key) == 1 {
+			regErr.add(tomlKeyLine(data, key[0]), "unknown key %q", key[0])
+		}
+	}
+
+	var lines []int
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; s.Scan(); n++ {
+		t := strings.TrimSpace(s.Text())
+		if strings.HasPrefix(t, "[[") && strings.TrimSpace(strings.Trim(t, "[]")) == "linters" {
+			lines = append(lines, n)
+		}
+	}
+
+	entries := make([]linterEntry, len(reg.Linters))
+	for i, fields := range reg.Linters {
+		entries[i].fields = fields
+		if i < len(lines) {
+			entries[i].line = lines[i]
+		}
+	}
+
+	return entries, nil
+}
+
+// tomlKeyLine returns the line a top-level key is defined on: either
+// as "key = value" before the first table, or as the header of a
+// table [key], [key.sub] or [[key]]. It returns 0 if the line can't be
+// found.
+func tomlKeyLine(data []byte, key string) int {
+	unquote := func(s string) string {
+		return strings.Trim(strings.TrimSpace(s), "\"'")
+	}
+
+	inTable := false
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; s.Scan(); n++ {
+		t := strings.TrimSpace(s.Text())
+
+		if strings.HasPrefix(t, "[") {
+			inTable = true
+			name := strings.Trim(strings.SplitN(t, "]", 2)[0], "[ ")
+			if unquote(strings.SplitN(name, ".", 2)[0]) == key {
+				return n
+			}
+			continue
+		}
+
+		if i := strings.IndexAny(t, "=."); !inTable && i > 0 && unquote(t[:i]) == key {
+			return n
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,119 @@
+package golinters
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRegistry(t *testing.T) {
+	want := []linter{
+		{name: "errcheck", cmd: "errcheck", path: "github.com/kisielk/errcheck", tags: []string{"bugs"}},
+		{name: "vetshadow", cmd: "go tool vet --shadow", path: "github.com/golang/go/src/cmd/vet", comment: "same as vet"},
+	}
+
+	tests := []struct {
+		file string
+		data string
+	}{
+		{"linters.yaml", `
+linters:
+  - name: errcheck
+    path: github.com/kisielk/errcheck
+    tags: [bugs]
+  - name: vetshadow
+    cmd: go tool vet --shadow
+    path: github.com/golang/go/src/cmd/vet
+    comment: same as vet
+`},
+		{"linters.YML", `linters: [{name: errcheck, path: github.com/kisielk/errcheck, tags: [bugs]}, {name: vetshadow, cmd: go tool vet --shadow, path: github.com/golang/go/src/cmd/vet, comment: same as vet}]`},
+		{"linters.toml", `
+[[linters]]
+name = "errcheck"
+path = "github.com/kisielk/errcheck"
+tags = ["bugs"]
+
+[[linters]]
+name = "vetshadow"
+cmd = "go tool vet --shadow"
+path = "github.com/golang/go/src/cmd/vet"
+comment = "same as vet"
+`},
+		{"linters.json", `{
+  "linters": [
+    {"name": "errcheck", "path": "github.com/kisielk/errcheck", "tags": ["bugs"]},
+    {"name": "vetshadow", "cmd": "go tool vet --shadow", "path": "github.com/golang/go/src/cmd/vet", "comment": "same as vet"}
+  ]
+}`},
+	}
+
+	for _, test := range tests {
+		got, err := parseRegistry(test.file, []byte(test.data))
+		if err != nil {
+			t.Errorf("%s: %v", test.file, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %+v, want %+v", test.file, got, want)
+		}
+	}
+}
+
+func TestParseRegistryErrors(t *testing.T) {
+	tests := []struct {
+		file string
+		data string
+		err  string
+	}{
+		{"linters.txt", ``, `linters.txt: unknown registry format ".txt" (use .yaml, .toml or .json)`},
+
+		// YAML
+		{"r.yaml", ``, `r.yaml: no linters defined`},
+		{"r.yaml", "linters: [\n", "r.yaml: yaml: line 1: did not find expected node content"},
+		{"r.yaml", "- a\n- b\n", `r.yaml:1: expected a mapping with a "linters" key`},
+		{"r.yaml", "linters:\n  - name: a\n    path: a.org/a\nother: 1\n", `r.yaml:4: unknown key "other"`},
+		{"r.yaml", "linters:\n  name: a\n", `r.yaml:2: "linters" must be a list`},
+		{"r.yaml", "linters:\n  - just a string\n", "r.yaml:2: malformed entry: yaml: unmarshal errors:\n  line 2: cannot unmarshal !!str `just a ...` into map[string]interface {}"},
+		{"r.yaml", "linters: []\n", `r.yaml: no linters defined`},
+
+		// Entries, validated the same for all formats.
+		{"r.yaml", "linters:\n  - name: a\n    path: a.org/a\n    url: x\n", `r.yaml:2: unknown field "url"`},
+		{"r.yaml", "linters:\n  - name: [a]\n    path: a.org/a\n", "r.yaml:2: field \"name\" must be a string\nr.yaml:2: empty name"},
+		{"r.yaml", "linters:\n  - name: a\n    path: a.org/a\n    tags: bugs\n", `r.yaml:2: field "tags" must be a list of strings`},
+		{"r.yaml", "linters:\n  - name: a\n    path: a.org/a\n    tags: [1, [2]]\n", `r.yaml:2: field "tags" must be a list of strings`},
+		{"r.yaml", "linters:\n  - path: a.org/a\n", `r.yaml:2: missing name`},
+		{"r.yaml", "linters:\n  - name: \" \"\n    path: a.org/a\n", `r.yaml:2: empty name`},
+		{"r.yaml", "linters:\n  - name: a\n", `r.yaml:2: missing path`},
+		{"r.yaml", "linters:\n  - name: a\n    path: \"\"\n", `r.yaml:2: empty path`},
+		{"r.yaml", "linters:\n  - name: a\n    path: a.org/a b\n", `r.yaml:2: malformed path "a.org/a b"`},
+		{"r.yaml", "linters:\n  - name: a\n    path: /a\n", `r.yaml:2: malformed path "/a"`},
+		{"r.yaml", "linters:\n  - name: a\n    path: a.org/a\n  - name: a\n    path: a.org/b\n", `r.yaml:4: duplicate linter "a" (first defined on line 2)`},
+
+		// TOML
+		{"r.toml", "[[linters]]\nname = \n", "r.toml:2: expected value but found '\\n' instead"},
+		{"r.toml", "other = 1\n\n[[linters]]\nname = \"a\"\npath = \"a.org/a\"\n", `r.toml:1: unknown key "other"`},
+		{"r.toml", "[[linters]]\nname = \"a\"\npath = \"a.org/a\"\n\n[other]\nx = 1\n", `r.toml:5: unknown key "other"`},
+		{"r.toml", "[[linters]]\nname = \"a\"\npath = \"a.org/a\"\n\n[[linters]]\nname = \"b\"\n", `r.toml:5: missing path`},
+		{"r.toml", "[[linters]]\nname = \"a\"\npath = \"a.org/a\"\n\n[[linters]]\nname = \"a\"\npath = \"a.org/b\"\n", `r.toml:5: duplicate linter "a" (first defined on line 1)`},
+		{"r.toml", "\n", `r.toml: no linters defined`},
+
+		// JSON
+		{"r.json", "{\n  \"linters\": [\n    {\"name\": \"a\",}\n  ]\n}", `r.json:3: invalid character '}' looking for beginning of object key string`},
+		{"r.json", "[]", `r.json:1: expected "{"`},
+		{"r.json", "{\n  \"linters\": {}\n}", `r.json:2: expected "["`},
+		{"r.json", "{\n  \"linters\": [\n    {\"name\": \"a\", \"path\": \"a.org/a\"}\n  ],\n  \"other\": 1\n}", `r.json:5: unknown key "other"`},
+		{"r.json", "{\n  \"linters\": [\n    \"a\"\n  ]\n}", `r.json:3: malformed entry: json: cannot unmarshal string into Go value of type map[string]interface {}`},
+		{"r.json", "{\n  \"linters\": [\n    {\"name\": \"a\"}\n  ]\n}", `r.json:3: missing path`},
+		{"r.json", "{\"linters\": [", `r.json:1: unexpected end of JSON input`},
+	}
+
+	for _, test := range tests {
+		_, err := parseRegistry(test.file, []byte(test.data))
+		if err == nil {
+			t.Errorf("%s %q: got no error, want %q", test.file, test.data, test.err)
+			continue
+		}
+		if err.Error() != test.err {
+			t.Errorf("%s %q:\ngot  %q\nwant %q", test.file, test.data, err, test.err)
+		}
+	}
+}
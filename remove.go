@@ -1,30 +1,29 @@
 package golinters
 
 import (
-	"log"
-	"os"
-	"strings"
+	"context"
 
-	"github.com/thomasheller/gopath"
+	"github.com/thomasheller/golinters/fetch"
 )
 
-// RemoveAllRepos deletes the entire source code repository in
-// GOPATH/src for all known linters. Be careful.
-func RemoveAllRepos() {
-	for _, linter := range list() {
-		parts := strings.Split(linter.path, "/")
+// Remove deletes what was fetched for the linters in the registry and
+// Options.Add that are selected by Options.Filter. Only directories
+// recorded in the manifest of Options.CacheDir are deleted, and only
+// if no other package was fetched to them and they have no uncommitted
+// changes (see fetch.Fetcher.Remove). If dryRun is true, nothing is
+// deleted. The result lists each directory, and why it was kept.
+func Remove(ctx context.Context, opts Options, dryRun bool) ([]fetch.Removal, error) {
+	linters, err := opts.linters()
+	if err != nil {
+		return nil, err
+	}
 
-		p, err := gopath.Join("src", parts[0], parts[1], parts[2])
-		if err != nil {
-			log.Printf("Error getting path to linter %s: %v", linter.name, err)
-			continue
-		}
+	var paths []string
+	for _, l := range linters {
+		paths = append(paths, l.path)
+	}
 
-		log.Printf("removing %s\n", p)
+	fetcher := &fetch.Fetcher{Dir: opts.CacheDir}
 
-		err = os.RemoveAll(p)
-		if err != nil {
-			log.Printf("Error removing linter %s: %v", linter.name, err)
-		}
-	}
+	return fetcher.Remove(ctx, paths, dryRun)
 }
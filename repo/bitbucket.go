@@ -0,0 +1,95 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+func init() {
+	Register(&BitbucketProvider{})
+}
+
+// BitbucketProvider fetches metadata of repositories on Bitbucket
+// Cloud, using its 2.0 API.
+type BitbucketProvider struct {
+	// BaseURL is the URL repositories are on. If empty,
+	// https://bitbucket.org is used.
+	BaseURL string
+	// APIURL is the URL of the API. If empty,
+	// https://api.bitbucket.org is used for bitbucket.org, and
+	// BaseURL/api otherwise.
+	APIURL string
+	// Username and AppPassword are used for the API, if not empty.
+	Username    string
+	AppPassword string
+	// Client is used for the API. If nil, a client with a timeout
+	// of 30 seconds is used.
+	Client *http.Client
+}
+
+func (p *BitbucketProvider) baseURL() string {
+	if p.BaseURL == "" {
+		return "https://bitbucket.org"
+	}
+	return strings.TrimSuffix(p.BaseURL, "/")
+}
+
+func (p *BitbucketProvider) apiURL() string {
+	switch {
+	case p.APIURL != "":
+		return strings.TrimSuffix(p.APIURL, "/")
+	case p.BaseURL == "":
+		return "https://api.bitbucket.org"
+	}
+	return p.baseURL() + "/api"
+}
+
+func (p *BitbucketProvider) Match(root *Root) bool {
+	_, ok := root.repoName(p.baseURL(), 2)
+	return ok
+}
+
+// bitbucketRepo is the part of a Bitbucket repository that is used.
+type bitbucketRepo struct {
+	Links struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"links"`
+	Owner struct {
+		DisplayName string `json:"display_name"`
+		Nickname    string `json:"nickname"`
+	} `json:"owner"`
+}
+
+func (p *BitbucketProvider) Info(ctx context.Context, root *Root) (*Repository, error) {
+	name, ok := root.repoName(p.baseURL(), 2)
+	if !ok {
+		return nil, errors.New("not a Bitbucket repository")
+	}
+
+	header := make(http.Header)
+	if p.Username != "" && p.AppPassword != "" {
+		header.Set("Authorization", basicAuth(p.Username, p.AppPassword))
+	}
+
+	var repo bitbucketRepo
+	if err := getJSON(ctx, p.Client, p.apiURL()+"/2.0/repositories/"+name, header, &repo); err != nil {
+		return nil, err
+	}
+
+	r := &Repository{Maintainer: repo.Owner.DisplayName, URL: repo.Links.HTML.Href}
+	if r.Maintainer == "" {
+		r.Maintainer = repo.Owner.Nickname
+	}
+
+	return r, nil
+}
+
+// BlobURL returns the URL of a line in a file, see
+// Repository.BlobURL.
+func (p *BitbucketProvider) BlobURL(repo *Repository, rev, file string, line int) string {
+	return lineURL(repo.URL, "src/"+rev, file, "#lines-", line)
+}
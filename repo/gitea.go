@@ -0,0 +1,84 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+func init() {
+	Register(&GiteaProvider{BaseURL: "https://codeberg.org"})
+}
+
+// GiteaProvider fetches metadata of repositories on a Gitea (or
+// Forgejo) instance. Codeberg is registered by default; register
+// another GiteaProvider for self-hosted instances.
+type GiteaProvider struct {
+	// BaseURL is the URL of the instance.
+	BaseURL string
+	// Token is an access token, if any.
+	Token string
+	// Client is used for the API. If nil, a client with a timeout
+	// of 30 seconds is used.
+	Client *http.Client
+}
+
+func (p *GiteaProvider) baseURL() string {
+	return strings.TrimSuffix(p.BaseURL, "/")
+}
+
+func (p *GiteaProvider) Match(root *Root) bool {
+	if p.BaseURL == "" {
+		return false
+	}
+	_, ok := root.repoName(p.baseURL(), 2)
+	return ok
+}
+
+// giteaRepo is the part of a Gitea repository that is used.
+type giteaRepo struct {
+	HTMLURL string `json:"html_url"`
+	Owner   struct {
+		Login    string `json:"login"`
+		FullName string `json:"full_name"`
+	} `json:"owner"`
+}
+
+func (p *GiteaProvider) Info(ctx context.Context, root *Root) (*Repository, error) {
+	name, ok := root.repoName(p.baseURL(), 2)
+	if p.BaseURL == "" || !ok {
+		return nil, errors.New("not a Gitea repository")
+	}
+
+	header := make(http.Header)
+	if p.Token != "" {
+		header.Set("Authorization", "token "+p.Token)
+	}
+
+	var repo giteaRepo
+	if err := getJSON(ctx, p.Client, p.baseURL()+"/api/v1/repos/"+name, header, &repo); err != nil {
+		return nil, err
+	}
+
+	r := &Repository{Maintainer: repo.Owner.FullName, URL: repo.HTMLURL}
+	if r.Maintainer == "" {
+		r.Maintainer = repo.Owner.Login
+	}
+
+	return r, nil
+}
+
+// BlobURL returns the URL of a line in a file, see
+// Repository.BlobURL. Gitea has separate pages for commits and tags.
+func (p *GiteaProvider) BlobURL(repo *Repository, rev, file string, line int) string {
+	kind := "tag"
+	if commitHash.MatchString(rev) {
+		kind = "commit"
+	}
+	return lineURL(repo.URL, "src/"+kind+"/"+rev, file, "#L", line)
+}
+
+// commitHash matches (abbreviated) commit hashes.
+var commitHash = regexp.MustCompile(`^[0-9a-f]{7,64}$`)
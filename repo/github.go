@@ -1,11 +1,12 @@
 package repo
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"strings"
-
-	"github.com/bndr/gopencils"
 )
 
 // GitHubAuth represents authentication data for the GitHub API.
@@ -17,65 +18,119 @@ type GitHubAuth struct {
 }
 
 type repo struct {
-	Owner    owner
-	HTML_URL string
+	Owner   owner  `json:"owner"`
+	HTMLURL string `json:"html_url"`
 }
 
 type owner struct {
-	Login string
+	Login string `json:"login"`
 }
 
 type user struct {
-	Login string
-	Name  string
+	Login string `json:"login"`
+	Name  string `json:"name"`
 }
 
-// Repo fetches basic metadata of a GitHub repository identified by
-// its import paths. Import paths that are not recognizable GitHub
-// repositories return an error.
-func GitHub(path string, githubAuth *GitHubAuth) (*Repository, error) {
-	if !strings.HasPrefix(path, "github.com") {
-		return nil, errors.New("not a GitHub repository")
-	}
+func init() {
+	Register(&GitHubProvider{})
+}
 
-	var auth *gopencils.BasicAuth
+// GitHubProvider fetches metadata of repositories on GitHub, and of
+// vanity import paths whose repository or source is on GitHub.
+type GitHubProvider struct {
+	// BaseURL is the URL of the GitHub instance. If empty,
+	// https://github.com is used.
+	BaseURL string
+	// APIURL is the URL of its API. If empty, https://api.github.com
+	// is used for github.com, and BaseURL/api/v3 for GitHub
+	// Enterprise instances.
+	APIURL string
+	// Auth is used for the API, if not empty.
+	Auth GitHubAuth
+	// Client is used for the API. If nil, a client with a timeout
+	// of 30 seconds is used.
+	Client *http.Client
+}
 
-	if githubAuth.Username != "" && githubAuth.Token != "" {
-		auth = &gopencils.BasicAuth{githubAuth.Username, githubAuth.Token}
+func (p *GitHubProvider) baseURL() string {
+	if p.BaseURL == "" {
+		return "https://github.com"
 	}
+	return p.BaseURL
+}
 
-	api := gopencils.Api("https://api.github.com", auth)
-
-	repos := api.Res("repos")
-	users := api.Res("users")
+func (p *GitHubProvider) apiURL() string {
+	switch {
+	case p.APIURL != "":
+		return p.APIURL
+	case p.BaseURL == "":
+		return "https://api.github.com"
+	}
+	return strings.TrimSuffix(p.BaseURL, "/") + "/api/v3"
+}
 
-	parts := strings.Split(path, "/")
-	repoName := parts[1] + "/" + parts[2]
+// withAuth returns p, or a copy of it that uses auth if p is for
+// github.com and has no credentials of its own.
+func (p *GitHubProvider) withAuth(auth GitHubAuth) *GitHubProvider {
+	if p.BaseURL != "" || p.Auth != (GitHubAuth{}) || auth == (GitHubAuth{}) {
+		return p
+	}
+	c := *p
+	c.Auth = auth
+	return &c
+}
 
-	var res *gopencils.Resource
-	var err error
+func (p *GitHubProvider) Match(root *Root) bool {
+	_, ok := root.repoName(p.baseURL(), 2)
+	return ok
+}
 
-	r := new(repo)
+func (p *GitHubProvider) Info(ctx context.Context, root *Root) (*Repository, error) {
+	repoName, ok := root.repoName(p.baseURL(), 2)
+	if !ok {
+		return nil, errors.New("not a GitHub repository")
+	}
+	return gitHub(ctx, p.Client, p.apiURL(), repoName, &p.Auth)
+}
 
-	if res, err = repos.Id(repoName, r).Get(); err != nil {
+// GitHub fetches basic metadata of the GitHub repository of the
+// package with the given import path. Import paths whose repository
+// isn't on GitHub return an error.
+//
+// Deprecated: Use Info, which works for all registered providers.
+func GitHub(path string, githubAuth *GitHubAuth) (*Repository, error) {
+	r, err := DefaultResolver.Info(context.Background(), path, githubAuth)
+	if err != nil {
 		return nil, err
 	}
-	if res.Raw.StatusCode >= 400 {
-		return nil, errorMsg(res.Raw.StatusCode)
+	if _, ok := r.provider.(*GitHubProvider); !ok {
+		return nil, errors.New("not a GitHub repository")
 	}
+	return r, nil
+}
 
-	username := r.Owner.Login
+// gitHub fetches the metadata of the GitHub repository with the given
+// "owner/name" from the API at apiURL, using client.
+func gitHub(ctx context.Context, client *http.Client, apiURL, repoName string, githubAuth *GitHubAuth) (*Repository, error) {
+	header := make(http.Header)
+	header.Set("Accept", "application/vnd.github+json")
+	if githubAuth.Username != "" && githubAuth.Token != "" {
+		header.Set("Authorization", basicAuth(githubAuth.Username, githubAuth.Token))
+	}
 
-	u := new(user)
+	apiURL = strings.TrimSuffix(apiURL, "/")
 
-	if res, err = users.Id(username, u).Get(); err != nil {
-		return nil, err
+	var r repo
+	if err := getJSON(ctx, client, apiURL+"/repos/"+repoName, header, &r); err != nil {
+		return nil, gitHubError(err)
 	}
-	if res.Raw.StatusCode >= 400 {
-		return nil, errorMsg(res.Raw.StatusCode)
+
+	var u user
+	if err := getJSON(ctx, client, apiURL+"/users/"+url.PathEscape(r.Owner.Login), header, &u); err != nil {
+		return nil, gitHubError(err)
 	}
 
-	result := &Repository{u.Name, r.HTML_URL}
+	result := &Repository{Maintainer: u.Name, URL: r.HTMLURL}
 
 	if result.Maintainer == "" {
 		result.Maintainer = u.Login
@@ -84,10 +139,11 @@ func GitHub(path string, githubAuth *GitHubAuth) (*Repository, error) {
 	return result, nil
 }
 
-func errorMsg(statusCode int) error {
-	if statusCode == 403 {
-		return errors.New("Error 403 - possibly rate limit exceeded. Did you supply GitHub credentials?")
-	} else {
-		return fmt.Errorf("Error %d", statusCode)
+// gitHubError explains errors of the GitHub API.
+func gitHubError(err error) error {
+	var se *statusError
+	if errors.As(err, &se) && se.code == http.StatusForbidden {
+		return fmt.Errorf("%v - possibly rate limit exceeded. Did you supply GitHub credentials?", err)
 	}
+	return err
 }
@@ -0,0 +1,83 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+func init() {
+	Register(&GitLabProvider{})
+}
+
+// GitLabProvider fetches metadata of repositories on GitLab.
+type GitLabProvider struct {
+	// BaseURL is the URL of the GitLab instance. If empty,
+	// https://gitlab.com is used.
+	BaseURL string
+	// Token is a personal access token, if any.
+	Token string
+	// Client is used for the API. If nil, a client with a timeout
+	// of 30 seconds is used.
+	Client *http.Client
+}
+
+func (p *GitLabProvider) baseURL() string {
+	if p.BaseURL == "" {
+		return "https://gitlab.com"
+	}
+	return strings.TrimSuffix(p.BaseURL, "/")
+}
+
+func (p *GitLabProvider) Match(root *Root) bool {
+	_, ok := root.repoName(p.baseURL(), 0)
+	return ok
+}
+
+// gitLabProject is the part of a GitLab project that is used.
+type gitLabProject struct {
+	WebURL    string `json:"web_url"`
+	Namespace struct {
+		Name string `json:"name"`
+	} `json:"namespace"`
+	// Owner is only set for projects of users, not of groups.
+	Owner *struct {
+		Name     string `json:"name"`
+		Username string `json:"username"`
+	} `json:"owner"`
+}
+
+func (p *GitLabProvider) Info(ctx context.Context, root *Root) (*Repository, error) {
+	name, ok := root.repoName(p.baseURL(), 0)
+	if !ok {
+		return nil, errors.New("not a GitLab repository")
+	}
+
+	header := make(http.Header)
+	if p.Token != "" {
+		header.Set("PRIVATE-TOKEN", p.Token)
+	}
+
+	var project gitLabProject
+	if err := getJSON(ctx, p.Client, p.baseURL()+"/api/v4/projects/"+url.PathEscape(name), header, &project); err != nil {
+		return nil, err
+	}
+
+	r := &Repository{Maintainer: project.Namespace.Name, URL: project.WebURL}
+	if o := project.Owner; o != nil {
+		r.Maintainer = o.Name
+		if r.Maintainer == "" {
+			r.Maintainer = o.Username
+		}
+	}
+
+	return r, nil
+}
+
+// BlobURL returns the URL of a line in a file, see
+// Repository.BlobURL.
+func (p *GitLabProvider) BlobURL(repo *Repository, rev, file string, line int) string {
+	return lineURL(repo.URL, "-/blob/"+rev, file, "#L", line)
+}
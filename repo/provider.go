@@ -0,0 +1,151 @@
+package repo
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Provider fetches repository metadata from a hosting provider.
+type Provider interface {
+	// Match reports whether the provider hosts the repository.
+	Match(root *Root) bool
+	// Info fetches the metadata of a repository it matches.
+	Info(ctx context.Context, root *Root) (*Repository, error)
+}
+
+var (
+	providersMu sync.Mutex
+	providers   []Provider
+)
+
+// Register makes a provider available to Info. Providers are tried
+// in the order they were registered, and the first one that matches
+// a repository is used.
+func Register(p Provider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers = append(providers, p)
+}
+
+// Providers returns the registered providers.
+func Providers() []Provider {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	return append([]Provider{}, providers...)
+}
+
+// repoName returns the path of the repository on the host at base,
+// e.g. "dominikh/go-tools" for "https://github.com/dominikh/go-tools.git"
+// on "https://github.com", if its URL or the home page of its source
+// is there. Only the first n path elements are returned, or all up to
+// a "-" element if n is 0, as used by GitLab for pages of a project.
+func (r *Root) repoName(base string, n int) (string, bool) {
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", false
+	}
+	prefix := strings.Trim(b.Path, "/")
+
+	for _, s := range r.urls() {
+		u, err := url.Parse(s)
+		if err != nil || !strings.EqualFold(u.Hostname(), b.Hostname()) {
+			continue
+		}
+
+		p := strings.Trim(u.Path, "/")
+		if prefix != "" {
+			if !strings.HasPrefix(p, prefix+"/") {
+				continue
+			}
+			p = strings.TrimPrefix(p, prefix+"/")
+		}
+
+		parts := strings.Split(p, "/")
+		if n == 0 {
+			for i, part := range parts {
+				if part == "-" {
+					parts = parts[:i]
+					break
+				}
+			}
+		} else if len(parts) >= n {
+			parts = parts[:n]
+		} else {
+			continue
+		}
+
+		if len(parts) == 0 || contains(parts, "") {
+			continue
+		}
+		return strings.TrimSuffix(strings.Join(parts, "/"), ".git"), true
+	}
+
+	return "", false
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
+
+// getJSON decodes the JSON response to a GET request of u into v.
+// header is added to the request.
+func getJSON(ctx context.Context, client *http.Client, u string, header http.Header, v interface{}) error {
+	if client == nil {
+		client = defaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return err
+	}
+	for k, vs := range header {
+		req.Header[k] = vs
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 400 {
+		return &statusError{url: u, code: res.StatusCode, status: res.Status}
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return fmt.Errorf("%s: %v", u, err)
+	}
+
+	return nil
+}
+
+// statusError is returned by getJSON for error responses.
+type statusError struct {
+	url    string
+	code   int
+	status string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s: %s", e.url, e.status)
+}
+
+// basicAuth returns the Authorization header for HTTP basic
+// authentication.
+func basicAuth(username, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+}
@@ -0,0 +1,228 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// internalProvider is a provider for a company's Git server.
+type internalProvider struct{}
+
+func (internalProvider) Match(root *Root) bool {
+	return strings.HasPrefix(root.Repo, "ssh://git.internal.example/")
+}
+
+func (internalProvider) Info(ctx context.Context, root *Root) (*Repository, error) {
+	name := strings.TrimPrefix(root.Path, "git.internal.example/")
+	return &Repository{
+		Maintainer: "Team " + name,
+		URL:        "https://git.internal.example/" + name,
+	}, nil
+}
+
+// restoreProviders undoes Register calls of the test when it ends.
+func restoreProviders(t *testing.T) {
+	saved := Providers()
+	t.Cleanup(func() {
+		providersMu.Lock()
+		defer providersMu.Unlock()
+		providers = saved
+	})
+}
+
+func TestRegister(t *testing.T) {
+	restoreProviders(t)
+
+	client, _ := metaServer(t)
+	r := &Resolver{Client: client}
+
+	path := "git.internal.example/team/tool/cmd/tool"
+
+	if _, err := r.Info(context.Background(), path, nil); err == nil {
+		t.Fatal("got no error before registering the provider")
+	}
+
+	Register(internalProvider{})
+
+	got, err := r.Info(context.Background(), path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Repository{Maintainer: "Team team/tool", URL: "https://git.internal.example/team/tool", provider: internalProvider{}}
+	if *got != want {
+		t.Errorf("got %+v, want %+v", *got, want)
+	}
+}
+
+func TestProviders(t *testing.T) {
+	// responses are the API responses of the providers, by path.
+	responses := map[string]string{
+		"/api/v4/projects/group%2Fsub%2Ftool": `{"web_url": "https://gitlab.example/group/sub/tool", "namespace": {"name": "Sub Group"}}`,
+		"/api/v4/projects/jane%2Ftool":        `{"web_url": "https://gitlab.example/jane/tool", "namespace": {"name": "jane"}, "owner": {"name": "Jane Doe", "username": "jane"}}`,
+		"/api/2.0/repositories/team/tool":     `{"links": {"html": {"href": "https://bitbucket.example/team/tool"}}, "owner": {"display_name": "The Team", "nickname": "team"}}`,
+		"/api/v1/repos/joe/tool":              `{"html_url": "https://gitea.example/joe/tool", "owner": {"login": "joe", "full_name": ""}}`,
+		"/api/v3/repos/team/tool":             `{"html_url": "https://github.example/team/tool", "owner": {"login": "team"}}`,
+		"/api/v3/users/team":                  `{"login": "team", "name": "The Team"}`,
+		"/gh/repos/team/tool":                 `{"html_url": "https://github.example/team/tool", "owner": {"login": "nobody"}}`,
+	}
+
+	var headers []http.Header
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header)
+		if r.URL.Path == "/gh/users/nobody" {
+			http.Error(w, "rate limit exceeded", http.StatusForbidden)
+			return
+		}
+		res, ok := responses[r.URL.EscapedPath()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, res)
+	}))
+	defer s.Close()
+
+	tests := []struct {
+		provider Provider
+		repo     string
+		want     *Repository
+		header   string
+		err      string
+	}{
+		{
+			provider: &GitHubProvider{BaseURL: s.URL, Auth: GitHubAuth{Username: "u", Token: "t"}},
+			repo:     s.URL + "/team/tool.git",
+			want:     &Repository{Maintainer: "The Team", URL: "https://github.example/team/tool"},
+			header:   "Authorization: Basic dTp0",
+		},
+		{
+			provider: &GitHubProvider{BaseURL: s.URL, APIURL: s.URL + "/gh/"},
+			repo:     s.URL + "/team/tool",
+			err:      "403 Forbidden - possibly rate limit exceeded",
+		},
+		{
+			provider: &GitLabProvider{BaseURL: s.URL, Token: "secret"},
+			repo:     s.URL + "/group/sub/tool.git",
+			want:     &Repository{Maintainer: "Sub Group", URL: "https://gitlab.example/group/sub/tool"},
+			header:   "Private-Token: secret",
+		},
+		{
+			provider: &GitLabProvider{BaseURL: s.URL + "/"},
+			repo:     s.URL + "/jane/tool",
+			want:     &Repository{Maintainer: "Jane Doe", URL: "https://gitlab.example/jane/tool"},
+		},
+		{
+			provider: &BitbucketProvider{BaseURL: s.URL, Username: "u", AppPassword: "p"},
+			repo:     s.URL + "/team/tool",
+			want:     &Repository{Maintainer: "The Team", URL: "https://bitbucket.example/team/tool"},
+			header:   "Authorization: Basic dTpw",
+		},
+		{
+			provider: &GiteaProvider{BaseURL: s.URL, Token: "secret"},
+			repo:     s.URL + "/joe/tool.git",
+			want:     &Repository{Maintainer: "joe", URL: "https://gitea.example/joe/tool"},
+			header:   "Authorization: token secret",
+		},
+		{
+			provider: &GiteaProvider{BaseURL: s.URL},
+			repo:     s.URL + "/joe/missing",
+			err:      "404 Not Found",
+		},
+	}
+
+	for _, test := range tests {
+		headers = nil
+		root := &Root{Path: "example.com/tool", VCS: "git", Repo: test.repo}
+
+		if !test.provider.Match(root) {
+			t.Errorf("%T doesn't match %s", test.provider, test.repo)
+			continue
+		}
+
+		got, err := test.provider.Info(context.Background(), root)
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%T.Info(%s): got error %v, want %q", test.provider, test.repo, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%T.Info(%s): %v", test.provider, test.repo, err)
+			continue
+		}
+		if *got != *test.want {
+			t.Errorf("%T.Info(%s) = %+v, want %+v", test.provider, test.repo, *got, *test.want)
+		}
+
+		if test.header != "" {
+			kv := strings.SplitN(test.header, ": ", 2)
+			for _, h := range headers {
+				if h.Get(kv[0]) != kv[1] {
+					t.Errorf("%T.Info(%s): got headers %v, want %s", test.provider, test.repo, h, test.header)
+				}
+			}
+			if len(headers) == 0 {
+				t.Errorf("%T.Info(%s): no requests", test.provider, test.repo)
+			}
+		}
+	}
+}
+
+func TestProvidersMatch(t *testing.T) {
+	tests := []struct {
+		provider Provider
+		repo     string
+		match    bool
+	}{
+		{&GitHubProvider{}, "https://github.com/kisielk/errcheck", true},
+		{&GitHubProvider{}, "https://gitlab.com/kisielk/errcheck", false},
+		{&GitHubProvider{BaseURL: "https://github.example"}, "https://github.example/team/tool", true},
+		{&GitLabProvider{}, "https://gitlab.com/group/sub/tool", true},
+		{&GitLabProvider{}, "https://github.com/group/tool", false},
+		{&BitbucketProvider{}, "https://bitbucket.org/team/tool", true},
+		{&BitbucketProvider{}, "https://bitbucket.org/team", false},
+		{&GiteaProvider{BaseURL: "https://codeberg.org"}, "https://codeberg.org/joe/tool", true},
+		{&GiteaProvider{}, "https://codeberg.org/joe/tool", false},
+	}
+
+	for _, test := range tests {
+		if match := test.provider.Match(&Root{Repo: test.repo}); match != test.match {
+			t.Errorf("%T%+v.Match(%s) = %v, want %v", test.provider, test.provider, test.repo, match, test.match)
+		}
+	}
+}
+
+func TestGitHubWithAuth(t *testing.T) {
+	auth := GitHubAuth{Username: "u", Token: "t"}
+	own := GitHubAuth{Username: "own", Token: "o"}
+
+	tests := []struct {
+		provider *GitHubProvider
+		auth     GitHubAuth
+		want     GitHubAuth
+	}{
+		{&GitHubProvider{}, auth, auth},
+		{&GitHubProvider{APIURL: "https://proxy.example"}, auth, auth},
+		{&GitHubProvider{}, GitHubAuth{}, GitHubAuth{}},
+		{&GitHubProvider{Auth: own}, auth, own},
+		// Credentials for github.com aren't sent anywhere else.
+		{&GitHubProvider{BaseURL: "https://github.example"}, auth, GitHubAuth{}},
+	}
+
+	for _, test := range tests {
+		before := *test.provider
+		p := test.provider.withAuth(test.auth)
+		if p.Auth != test.want {
+			t.Errorf("%+v.withAuth(%+v).Auth = %+v, want %+v", before, test.auth, p.Auth, test.want)
+		}
+		if *test.provider != before {
+			t.Errorf("%+v.withAuth(%+v) changed the registered provider", before, test.auth)
+		}
+	}
+}
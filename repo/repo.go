@@ -1,34 +1,94 @@
+// Package repo looks up the source code repositories of Go packages:
+// their roots, from the import path's structure or its go-import
+// meta tags, and their maintainers and URLs, from the APIs of the
+// hosts. Roots aren't resolved from VCS metadata on disk, since
+// packages are fetched as modules, which have none. WorkingCopy only
+// finds the working copies directories are in, so that local changes
+// can be checked for. Removing fetched packages needs no roots
+// either, since the fetch package records the directories it creates.
 package repo
 
 import (
-	"errors"
+	"context"
+	"fmt"
 	"strings"
 )
 
-// Repository describes GitHub repository metadata
+// Repository describes repository metadata.
 type Repository struct {
 	// Maintainer is the full name of the repository owner, or
 	// username if the real name is unknown.
-	Maintainer string
+	Maintainer string `json:"maintainer"`
 	// URL is the HTML URL of a repository that can be viewed in a
 	// webbrowser.
-	URL string
+	URL string `json:"url"`
+
+	// provider is the provider that Resolver.Info fetched the
+	// metadata with.
+	provider Provider
 }
 
-// Info returns information about source code repositories based on
-// the import path. Only a few common paths are currently supported.
-func Info(path string, gitHubAuth *GitHubAuth) (*Repository, error) {
-	if strings.HasPrefix(path, "github.com/") {
-		return GitHub(path, gitHubAuth)
+// BlobURLer is implemented by providers whose links to lines in files
+// don't have GitHub's form.
+type BlobURLer interface {
+	// BlobURL returns the URL of a line in a file at the given
+	// revision of repo, see Repository.BlobURL.
+	BlobURL(repo *Repository, rev, file string, line int) string
+}
+
+// BlobURL returns the URL of a line in a file at the given revision
+// of the repository. file is relative to the repository root, and
+// line is left out if it is 0. If the repository's provider is a
+// BlobURLer, it builds the URL; otherwise the URL has GitHub's form.
+func (r *Repository) BlobURL(rev, file string, line int) string {
+	if b, ok := r.provider.(BlobURLer); ok {
+		return b.BlobURL(r, rev, file, line)
 	}
+	return lineURL(r.URL, "blob/"+rev, file, "#L", line)
+}
+
+// lineURL returns the URL of a line in a file below the page at path
+// of the repository at repoURL, with the line number appended to
+// anchor, if known.
+func lineURL(repoURL, path, file, anchor string, line int) string {
+	u := fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(repoURL, "/"), path, strings.TrimPrefix(file, "/"))
+	if line > 0 {
+		u += fmt.Sprintf("%s%d", anchor, line)
+	}
+	return u
+}
+
+// Info returns information about the source code repository of the
+// package with the given import path, using DefaultResolver.
+func Info(path string, gitHubAuth *GitHubAuth) (*Repository, error) {
+	return DefaultResolver.Info(context.Background(), path, gitHubAuth)
+}
 
-	if strings.HasPrefix(path, "honnef.co/") {
-		return Honnef(path)
+// Info returns information about the source code repository of the
+// package with the given import path. The repository is resolved
+// first, so vanity import paths work too, then its metadata is
+// fetched by the first registered Provider that matches it. If
+// gitHubAuth is not nil, it is passed to the registered
+// GitHubProviders for github.com that have no credentials of their
+// own.
+func (r *Resolver) Info(ctx context.Context, path string, gitHubAuth *GitHubAuth) (*Repository, error) {
+	root, err := r.Resolve(ctx, path)
+	if err != nil {
+		return nil, err
 	}
 
-	if strings.HasPrefix(path, "golang.org/") {
-		return Golang(path)
+	for _, p := range Providers() {
+		if gp, ok := p.(*GitHubProvider); ok && gitHubAuth != nil {
+			p = gp.withAuth(*gitHubAuth)
+		}
+		if p.Match(root) {
+			repo, err := p.Info(ctx, root)
+			if repo != nil {
+				repo.provider = p
+			}
+			return repo, err
+		}
 	}
 
-	return nil, errors.New("not a recognized repository path")
+	return nil, fmt.Errorf("%s is not on a recognized repository host", root.Repo)
 }
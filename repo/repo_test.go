@@ -0,0 +1,94 @@
+package repo
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRepoName(t *testing.T) {
+	tests := []struct {
+		url  string
+		base string
+		n    int
+		name string
+	}{
+		{"https://github.com/kisielk/errcheck", "https://github.com", 2, "kisielk/errcheck"},
+		{"https://github.com/dominikh/go-tools.git", "https://github.com", 2, "dominikh/go-tools"},
+		{"https://github.com/golang/tools/", "https://github.com", 2, "golang/tools"},
+		{"https://github.com/golang/tools/tree/master", "https://github.com", 2, "golang/tools"},
+		{"https://github.com/golang", "https://github.com", 2, ""},
+		{"https://go.googlesource.com/tools", "https://github.com", 2, ""},
+		{"https://git.example.net/github.com/a/b", "https://github.com", 2, ""},
+		{"https://gitlab.com/group/sub/project.git", "https://gitlab.com", 0, "group/sub/project"},
+		{"https://gitlab.com/group/project/-/tree/main", "https://gitlab.com", 0, "group/project"},
+		{"ssh://git@git.example.com/team/tool.git", "https://git.example.com", 2, "team/tool"},
+		{"https://example.com/gitea/team/tool", "https://example.com/gitea/", 2, "team/tool"},
+		{"https://example.com/other/team/tool", "https://example.com/gitea", 2, ""},
+	}
+
+	for _, test := range tests {
+		root := &Root{Repo: test.url}
+		name, ok := root.repoName(test.base, test.n)
+		if name != test.name || ok != (test.name != "") {
+			t.Errorf("repoName(%q, %q, %d) = %q, %v, want %q", test.url, test.base, test.n, name, ok, test.name)
+		}
+	}
+}
+
+func TestInfoUnknownHost(t *testing.T) {
+	client, _ := metaServer(t)
+	r := &Resolver{Client: client}
+
+	_, err := r.Info(context.Background(), "git.example.net/tool/cmd/tool", &GitHubAuth{})
+	if err == nil || !strings.Contains(err.Error(), "https://git.example.net/tool.git is not on a recognized repository host") {
+		t.Errorf("got error %v, want unrecognized host", err)
+	}
+}
+
+func TestGitHubOtherHost(t *testing.T) {
+	restoreProviders(t)
+	Register(internalProvider{})
+
+	saved := DefaultResolver
+	defer func() { DefaultResolver = saved }()
+	client, _ := metaServer(t)
+	DefaultResolver = &Resolver{Client: client}
+
+	path := "git.internal.example/team/tool/cmd/tool"
+
+	if _, err := Info(path, &GitHubAuth{}); err != nil {
+		t.Fatalf("Info: %v", err)
+	}
+	if _, err := GitHub(path, &GitHubAuth{}); err == nil || err.Error() != "not a GitHub repository" {
+		t.Errorf("GitHub: got error %v, want not a GitHub repository", err)
+	}
+}
+
+func TestBlobURL(t *testing.T) {
+	tests := []struct {
+		repo *Repository
+		rev  string
+		file string
+		line int
+		want string
+	}{
+		{&Repository{URL: "https://github.com/a/b"}, "v1.0.0", "main.go", 12, "https://github.com/a/b/blob/v1.0.0/main.go#L12"},
+		{&Repository{URL: "https://github.com/a/b/", provider: &GitHubProvider{}}, "0123456789ab", "/cmd/x/main.go", 0, "https://github.com/a/b/blob/0123456789ab/cmd/x/main.go"},
+		{&Repository{URL: "https://gitlab.com/g/s/p", provider: &GitLabProvider{}}, "sub/v1.2.0", "sub/x.go", 3, "https://gitlab.com/g/s/p/-/blob/sub/v1.2.0/sub/x.go#L3"},
+		{&Repository{URL: "https://gitlab.com/g/p", provider: &GitLabProvider{}}, "v1.0.0", "x.go", 0, "https://gitlab.com/g/p/-/blob/v1.0.0/x.go"},
+		{&Repository{URL: "https://bitbucket.org/t/r", provider: &BitbucketProvider{}}, "v1.0.0", "x.go", 7, "https://bitbucket.org/t/r/src/v1.0.0/x.go#lines-7"},
+		{&Repository{URL: "https://bitbucket.org/t/r", provider: &BitbucketProvider{}}, "0123456789ab", "x.go", 0, "https://bitbucket.org/t/r/src/0123456789ab/x.go"},
+		{&Repository{URL: "https://codeberg.org/j/t", provider: &GiteaProvider{}}, "0123456789ab", "x.go", 5, "https://codeberg.org/j/t/src/commit/0123456789ab/x.go#L5"},
+		{&Repository{URL: "https://codeberg.org/j/t", provider: &GiteaProvider{}}, "v1.0.0", "x.go", 5, "https://codeberg.org/j/t/src/tag/v1.0.0/x.go#L5"},
+		{&Repository{URL: "https://codeberg.org/j/t", provider: &GiteaProvider{}}, "sub/v1.0.0", "sub/x.go", 0, "https://codeberg.org/j/t/src/tag/sub/v1.0.0/sub/x.go"},
+		// Providers that don't build links of their own get GitHub's.
+		{&Repository{URL: "https://git.internal.example/t/r", provider: internalProvider{}}, "v1.0.0", "x.go", 1, "https://git.internal.example/t/r/blob/v1.0.0/x.go#L1"},
+	}
+
+	for _, test := range tests {
+		if got := test.repo.BlobURL(test.rev, test.file, test.line); got != test.want {
+			t.Errorf("%T.BlobURL(%q, %q, %d) = %s, want %s", test.repo.provider, test.rev, test.file, test.line, got, test.want)
+		}
+	}
+}
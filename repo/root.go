@@ -0,0 +1,321 @@
+package repo
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Root is the root of a version control repository, and the import
+// path that corresponds to it.
+type Root struct {
+	// Path is the import path of the repository root, e.g.
+	// "github.com/kisielk/errcheck" for
+	// "github.com/kisielk/errcheck/internal/errcheck".
+	Path string `json:"path"`
+	// VCS is the version control system: "git", "hg", "svn" or
+	// "bzr".
+	VCS string `json:"vcs"`
+	// Repo is the URL the repository can be cloned from.
+	Repo string `json:"repo"`
+	// Source is where the source code can be browsed, as declared
+	// by a go-source meta tag, if any.
+	Source *Source `json:"source,omitempty"`
+}
+
+// Source is the content of a go-source meta tag, see
+// https://github.com/golang/gddo/wiki/Source-Code-Links. Its
+// directory and file URL templates aren't kept, since they link to
+// the default branch rather than the analyzed revision.
+type Source struct {
+	// Home is the URL of the repository's home page.
+	Home string `json:"home"`
+}
+
+// urls returns the URLs the repository is known by: the one it is
+// cloned from first, then the home page of its source.
+func (r *Root) urls() []string {
+	urls := []string{r.Repo}
+	if r.Source != nil && r.Source.Home != "" {
+		urls = append(urls, r.Source.Home)
+	}
+	return urls
+}
+
+// Subdir returns the directory of the package with the given import
+// path relative to the repository root, in slash-separated form. It
+// is "" for the root itself.
+func (r *Root) Subdir(path string) string {
+	if path == r.Path {
+		return ""
+	}
+	return strings.TrimPrefix(path, r.Path+"/")
+}
+
+// Resolver finds the repository roots of import paths. Paths on
+// well-known hosts are resolved by their structure, others, such as
+// vanity import paths, by the go-import and go-source meta tags
+// served at https://<path>?go-get=1, like the go command does.
+// Results are cached.
+type Resolver struct {
+	// Client is used to fetch meta tags. If nil, a client with a
+	// timeout of 30 seconds is used.
+	Client *http.Client
+
+	mu    sync.Mutex
+	roots []*Root
+}
+
+// DefaultResolver is the Resolver used by Info.
+var DefaultResolver = &Resolver{}
+
+var defaultClient = &http.Client{Timeout: 30 * time.Second}
+
+// staticRoot describes the repositories on a host whose import paths
+// have a fixed structure.
+type staticRoot struct {
+	prefix string
+	// re matches the import path of the root, with the named
+	// groups used by repo.
+	re   *regexp.Regexp
+	vcs  string
+	repo func(m map[string]string) string
+}
+
+var staticRoots = []staticRoot{
+	{
+		prefix: "github.com/",
+		re:     regexp.MustCompile(`^github\.com/(?P<user>[A-Za-z0-9_.\-]+)/(?P<repo>[A-Za-z0-9_.\-]+)`),
+		vcs:    "git",
+		repo:   func(m map[string]string) string { return "https://github.com/" + m["user"] + "/" + m["repo"] },
+	},
+	{
+		prefix: "bitbucket.org/",
+		re:     regexp.MustCompile(`^bitbucket\.org/(?P<user>[A-Za-z0-9_.\-]+)/(?P<repo>[A-Za-z0-9_.\-]+)`),
+		vcs:    "git",
+		repo:   func(m map[string]string) string { return "https://bitbucket.org/" + m["user"] + "/" + m["repo"] },
+	},
+	{
+		// gopkg.in/pkg.v1 is github.com/go-pkg/pkg, and
+		// gopkg.in/user/pkg.v1 is github.com/user/pkg.
+		prefix: "gopkg.in/",
+		re:     regexp.MustCompile(`^gopkg\.in/(?:(?P<user>[A-Za-z0-9][-A-Za-z0-9]*)/)?(?P<repo>[A-Za-z][-A-Za-z0-9]*)\.v[0-9]+(?:-unstable)?`),
+		vcs:    "git",
+		repo: func(m map[string]string) string {
+			user := m["user"]
+			if user == "" {
+				user = "go-" + m["repo"]
+			}
+			return "https://github.com/" + user + "/" + m["repo"]
+		},
+	},
+}
+
+// Resolve returns the repository root of the package with the given
+// import path.
+func (r *Resolver) Resolve(ctx context.Context, path string) (*Root, error) {
+	for _, s := range staticRoots {
+		if !strings.HasPrefix(path, s.prefix) {
+			continue
+		}
+
+		m := s.re.FindStringSubmatch(path)
+		if m == nil || len(m[0]) < len(path) && path[len(m[0])] != '/' {
+			return nil, fmt.Errorf("invalid import path %q", path)
+		}
+
+		groups := make(map[string]string)
+		for i, name := range s.re.SubexpNames() {
+			groups[name] = m[i]
+		}
+
+		return &Root{Path: m[0], VCS: s.vcs, Repo: s.repo(groups)}, nil
+	}
+
+	if root := r.cached(path); root != nil {
+		return root, nil
+	}
+
+	root, err := r.resolveMeta(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.roots = append(r.roots, root)
+	r.mu.Unlock()
+
+	return root, nil
+}
+
+// cached returns the cached root of path, or nil.
+func (r *Resolver) cached(path string) *Root {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, root := range r.roots {
+		if path == root.Path || strings.HasPrefix(path, root.Path+"/") {
+			return root
+		}
+	}
+	return nil
+}
+
+// resolveMeta resolves path by the go-import meta tags served for
+// it, and adds the go-source meta tag of the root, if any. Entries
+// for module proxies (VCS "mod") are ignored.
+func (r *Resolver) resolveMeta(ctx context.Context, path string) (*Root, error) {
+	client := r.Client
+	if client == nil {
+		client = defaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://"+path+"?go-get=1", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	imports, sources, err := parseMeta(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %v", req.URL, err)
+	}
+
+	var root *Root
+	for _, im := range imports {
+		if im.VCS == "mod" || path != im.Path && !strings.HasPrefix(path, im.Path+"/") {
+			continue
+		}
+		if root != nil {
+			return nil, fmt.Errorf("%s lists multiple go-import meta tags for %s", req.URL, path)
+		}
+		root = im
+	}
+
+	if root == nil {
+		if res.StatusCode >= 400 {
+			return nil, fmt.Errorf("%s: %s", req.URL, res.Status)
+		}
+		return nil, fmt.Errorf("%s has no go-import meta tag for %s", req.URL, path)
+	}
+
+	root.Source = sources[root.Path]
+
+	return root, nil
+}
+
+// parseMeta returns the go-import meta tags in the head of a HTML
+// page, and the go-source meta tags by import path. Like the go
+// command, it accepts sloppy HTML.
+func parseMeta(r io.Reader) ([]*Root, map[string]*Source, error) {
+	d := xml.NewDecoder(r)
+	d.Strict = false
+	d.AutoClose = xml.HTMLAutoClose
+	d.Entity = xml.HTMLEntity
+	d.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		switch strings.ToLower(charset) {
+		case "utf-8", "ascii":
+			return input, nil
+		}
+		return nil, fmt.Errorf("can't decode charset %q", charset)
+	}
+
+	var imports []*Root
+	sources := make(map[string]*Source)
+
+	for {
+		t, err := d.RawToken()
+		if err != nil {
+			if err == io.EOF || len(imports) > 0 {
+				break
+			}
+			return nil, nil, err
+		}
+
+		if e, ok := t.(xml.StartElement); ok && strings.EqualFold(e.Name.Local, "body") {
+			break
+		}
+		if e, ok := t.(xml.EndElement); ok && strings.EqualFold(e.Name.Local, "head") {
+			break
+		}
+
+		e, ok := t.(xml.StartElement)
+		if !ok || !strings.EqualFold(e.Name.Local, "meta") {
+			continue
+		}
+
+		f := strings.Fields(attr(e, "content"))
+
+		switch attr(e, "name") {
+		case "go-import":
+			if len(f) == 3 {
+				imports = append(imports, &Root{Path: f[0], VCS: f[1], Repo: f[2]})
+			}
+		case "go-source":
+			if len(f) == 4 {
+				sources[f[0]] = &Source{Home: f[1]}
+			}
+		}
+	}
+
+	return imports, sources, nil
+}
+
+// attr returns the value of the named attribute of e.
+func attr(e xml.StartElement, name string) string {
+	for _, a := range e.Attr {
+		if strings.EqualFold(a.Name.Local, name) {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// vcsDirs are the metadata directories of the supported version
+// control systems, by name.
+var vcsDirs = []struct{ vcs, dir string }{
+	{"git", ".git"},
+	{"hg", ".hg"},
+	{"svn", ".svn"},
+	{"bzr", ".bzr"},
+}
+
+// ErrNoWorkingCopy is returned by WorkingCopy for directories that
+// aren't under version control.
+var ErrNoWorkingCopy = errors.New("not in a working copy")
+
+// WorkingCopy returns the root directory of the working copy dir is
+// in, and its version control system, by looking for VCS metadata in
+// dir and its parents.
+func WorkingCopy(dir string) (root, vcs string, err error) {
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return "", "", err
+	}
+
+	for d := dir; ; d = filepath.Dir(d) {
+		for _, v := range vcsDirs {
+			if _, err := os.Stat(filepath.Join(d, v.dir)); err == nil {
+				return d, v.vcs, nil
+			}
+		}
+
+		if filepath.Dir(d) == d {
+			return "", "", ErrNoWorkingCopy
+		}
+	}
+}
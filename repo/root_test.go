@@ -0,0 +1,250 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// goImports are the go-import meta tags served by metaServer, by
+// host.
+var goImports = map[string]string{
+	"golang.org": `
+		<meta name="go-import" content="golang.org/x/tools git https://go.googlesource.com/tools">
+		<meta name="go-import" content="golang.org/x/tools mod https://proxy.golang.org">
+		<meta name="go-source" content="golang.org/x/tools https://github.com/golang/tools/ https://github.com/golang/tools/tree/master{/dir} https://github.com/golang/tools/blob/master{/dir}/{file}#L{line}">`,
+	"honnef.co": `<meta name="go-import" content="honnef.co/go/tools git https://github.com/dominikh/go-tools">`,
+	"mvdan.cc":  `<META NAME="go-import" CONTENT="mvdan.cc/unparam git https://github.com/mvdan/unparam"/>`,
+	"git.example.net": `
+		<meta name="go-import" content="git.example.net/tool git https://git.example.net/tool.git">
+		<meta name="go-source" content="git.example.net/tool https://git.example.net/tool _ _">`,
+	"git.internal.example": `<meta name="go-import" content="git.internal.example/team/tool git ssh://git.internal.example/team/tool">`,
+	"example.com": `
+		<meta name="go-import" content="example.com/a git https://example.com/a.git">
+		<meta name="go-import" content="example.com/a hg https://example.com/a">`,
+}
+
+// metaServer serves a HTML page with the go-import meta tags of the
+// requested host, and a client that sends all requests to it.
+func metaServer(t *testing.T) (*http.Client, *int32) {
+	requests := new(int32)
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		meta, ok := goImports[r.Host]
+		if !ok || r.URL.Query().Get("go-get") != "1" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, "<!DOCTYPE html>\n<html><head>%s</head><body><meta name=\"go-import\" content=\"ignored git https://example.org\"></body></html>", meta)
+	}))
+	t.Cleanup(s.Close)
+
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &http.Client{Transport: rewriteTransport{u}}, requests
+}
+
+// rewriteTransport sends requests to another server, keeping the
+// original host in the Host header.
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (rt rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Host = req.URL.Host
+	req.URL.Scheme, req.URL.Host = rt.target.Scheme, rt.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestResolve(t *testing.T) {
+	client, _ := metaServer(t)
+	r := &Resolver{Client: client}
+
+	tests := []struct {
+		path   string
+		root   string
+		vcs    string
+		repo   string
+		subdir string
+	}{
+		// The paths of the built-in linters and detectors.
+		{"github.com/opennota/check/cmd/aligncheck", "github.com/opennota/check", "git", "https://github.com/opennota/check", "cmd/aligncheck"},
+		{"github.com/tsenart/deadcode", "github.com/tsenart/deadcode", "git", "https://github.com/tsenart/deadcode", ""},
+		{"github.com/mibk/dupl", "github.com/mibk/dupl", "git", "https://github.com/mibk/dupl", ""},
+		{"github.com/kisielk/errcheck", "github.com/kisielk/errcheck", "git", "https://github.com/kisielk/errcheck", ""},
+		{"github.com/GoASTScanner/gas", "github.com/GoASTScanner/gas", "git", "https://github.com/GoASTScanner/gas", ""},
+		{"github.com/jgautheron/goconst/cmd/goconst", "github.com/jgautheron/goconst", "git", "https://github.com/jgautheron/goconst", "cmd/goconst"},
+		{"github.com/fzipp/gocyclo", "github.com/fzipp/gocyclo", "git", "https://github.com/fzipp/gocyclo", ""},
+		{"github.com/golang/go/src/cmd/gofmt", "github.com/golang/go", "git", "https://github.com/golang/go", "src/cmd/gofmt"},
+		{"golang.org/x/tools/cmd/goimports", "golang.org/x/tools", "git", "https://go.googlesource.com/tools", "cmd/goimports"},
+		{"github.com/golang/lint/golint", "github.com/golang/lint", "git", "https://github.com/golang/lint", "golint"},
+		{"honnef.co/go/tools/cmd/gosimple", "honnef.co/go/tools", "git", "https://github.com/dominikh/go-tools", "cmd/gosimple"},
+		{"golang.org/x/tools/cmd/gotype", "golang.org/x/tools", "git", "https://go.googlesource.com/tools", "cmd/gotype"},
+		{"github.com/gordonklaus/ineffassign", "github.com/gordonklaus/ineffassign", "git", "https://github.com/gordonklaus/ineffassign", ""},
+		{"github.com/mvdan/interfacer/cmd/interfacer", "github.com/mvdan/interfacer", "git", "https://github.com/mvdan/interfacer", "cmd/interfacer"},
+		{"github.com/walle/lll/cmd/lll", "github.com/walle/lll", "git", "https://github.com/walle/lll", "cmd/lll"},
+		{"github.com/client9/misspell/cmd/misspell", "github.com/client9/misspell", "git", "https://github.com/client9/misspell", "cmd/misspell"},
+		{"github.com/stripe/safesql", "github.com/stripe/safesql", "git", "https://github.com/stripe/safesql", ""},
+		{"honnef.co/go/tools/cmd/staticcheck", "honnef.co/go/tools", "git", "https://github.com/dominikh/go-tools", "cmd/staticcheck"},
+		{"github.com/opennota/check/cmd/structcheck", "github.com/opennota/check", "git", "https://github.com/opennota/check", "cmd/structcheck"},
+		{"github.com/mdempsky/unconvert", "github.com/mdempsky/unconvert", "git", "https://github.com/mdempsky/unconvert", ""},
+		{"github.com/mvdan/unparam", "github.com/mvdan/unparam", "git", "https://github.com/mvdan/unparam", ""},
+		{"honnef.co/go/tools/cmd/unused", "honnef.co/go/tools", "git", "https://github.com/dominikh/go-tools", "cmd/unused"},
+		{"github.com/opennota/check/cmd/varcheck", "github.com/opennota/check", "git", "https://github.com/opennota/check", "cmd/varcheck"},
+		{"github.com/golang/go/src/cmd/vet", "github.com/golang/go", "git", "https://github.com/golang/go", "src/cmd/vet"},
+		{"github.com/alecthomas/gometalinter", "github.com/alecthomas/gometalinter", "git", "https://github.com/alecthomas/gometalinter", ""},
+		{"github.com/mvdan/lint/cmd/metalint", "github.com/mvdan/lint", "git", "https://github.com/mvdan/lint", "cmd/metalint"},
+
+		// Paths that don't have the repository root in the
+		// first three components.
+		{"mvdan.cc/unparam", "mvdan.cc/unparam", "git", "https://github.com/mvdan/unparam", ""},
+		{"golang.org/x/tools/gopls", "golang.org/x/tools", "git", "https://go.googlesource.com/tools", "gopls"},
+		{"gopkg.in/yaml.v2", "gopkg.in/yaml.v2", "git", "https://github.com/go-yaml/yaml", ""},
+		{"gopkg.in/alecthomas/kingpin.v3-unstable", "gopkg.in/alecthomas/kingpin.v3-unstable", "git", "https://github.com/alecthomas/kingpin", ""},
+		{"gopkg.in/src-d/go-git.v4/plumbing", "gopkg.in/src-d/go-git.v4", "git", "https://github.com/src-d/go-git", "plumbing"},
+		{"bitbucket.org/user/repo/cmd/tool", "bitbucket.org/user/repo", "git", "https://bitbucket.org/user/repo", "cmd/tool"},
+	}
+
+	for _, test := range tests {
+		root, err := r.Resolve(context.Background(), test.path)
+		if err != nil {
+			t.Errorf("Resolve(%q): %v", test.path, err)
+			continue
+		}
+		if root.Path != test.root || root.VCS != test.vcs || root.Repo != test.repo {
+			t.Errorf("Resolve(%q) = %+v, want {Path:%s VCS:%s Repo:%s}", test.path, *root, test.root, test.vcs, test.repo)
+		}
+		if subdir := root.Subdir(test.path); subdir != test.subdir {
+			t.Errorf("Resolve(%q).Subdir() = %q, want %q", test.path, subdir, test.subdir)
+		}
+	}
+}
+
+func TestResolveSource(t *testing.T) {
+	client, _ := metaServer(t)
+	r := &Resolver{Client: client}
+
+	tests := []struct {
+		path   string
+		source *Source
+	}{
+		{"golang.org/x/tools/cmd/goimports", &Source{Home: "https://github.com/golang/tools/"}},
+		{"git.example.net/tool", &Source{Home: "https://git.example.net/tool"}},
+		{"honnef.co/go/tools/cmd/staticcheck", nil},
+		{"github.com/kisielk/errcheck", nil},
+	}
+
+	for _, test := range tests {
+		root, err := r.Resolve(context.Background(), test.path)
+		if err != nil {
+			t.Errorf("Resolve(%q): %v", test.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(root.Source, test.source) {
+			t.Errorf("Resolve(%q).Source = %+v, want %+v", test.path, root.Source, test.source)
+		}
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	client, _ := metaServer(t)
+	r := &Resolver{Client: client}
+
+	tests := []struct {
+		path string
+		err  string
+	}{
+		{"github.com/kisielk", "invalid import path"},
+		{"gopkg.in/yaml", "invalid import path"},
+		{"unknown.org/some/tool", "404 Not Found"},
+		{"honnef.co/go/other", "no go-import meta tag for honnef.co/go/other"},
+		{"example.com/a/b", "multiple go-import meta tags"},
+	}
+
+	for _, test := range tests {
+		root, err := r.Resolve(context.Background(), test.path)
+		if err == nil {
+			t.Errorf("Resolve(%q) = %+v, want error", test.path, *root)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.err) {
+			t.Errorf("Resolve(%q): got error %q, want %q", test.path, err, test.err)
+		}
+	}
+}
+
+func TestResolveCache(t *testing.T) {
+	client, requests := metaServer(t)
+	r := &Resolver{Client: client}
+
+	for _, path := range []string{
+		"honnef.co/go/tools/cmd/staticcheck",
+		"honnef.co/go/tools/cmd/unused",
+		"honnef.co/go/tools",
+	} {
+		if _, err := r.Resolve(context.Background(), path); err != nil {
+			t.Fatalf("Resolve(%q): %v", path, err)
+		}
+	}
+
+	if n := atomic.LoadInt32(requests); n != 1 {
+		t.Errorf("got %d requests, want 1", n)
+	}
+}
+
+func TestWorkingCopy(t *testing.T) {
+	tmp := t.TempDir()
+
+	for _, dir := range []string{
+		"git/.git",
+		"git/a/b",
+		"git/hg/.hg",
+		"git/hg/c",
+		"none",
+	} {
+		if err := os.MkdirAll(filepath.Join(tmp, filepath.FromSlash(dir)), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		dir  string
+		root string
+		vcs  string
+	}{
+		{"git", "git", "git"},
+		{"git/a/b", "git", "git"},
+		{"git/hg", "git/hg", "hg"},
+		{"git/hg/c", "git/hg", "hg"},
+	}
+
+	for _, test := range tests {
+		root, vcs, err := WorkingCopy(filepath.Join(tmp, filepath.FromSlash(test.dir)))
+		if err != nil {
+			t.Errorf("WorkingCopy(%q): %v", test.dir, err)
+			continue
+		}
+		if want := filepath.Join(tmp, filepath.FromSlash(test.root)); root != want || vcs != test.vcs {
+			t.Errorf("WorkingCopy(%q) = %s, %s, want %s, %s", test.dir, root, vcs, want, test.vcs)
+		}
+	}
+
+	// tmp itself may be in a working copy, so only check that the
+	// working copies above aren't found.
+	if root, _, err := WorkingCopy(filepath.Join(tmp, "none")); err == nil && strings.HasPrefix(root, tmp) {
+		t.Errorf("WorkingCopy(%q) = %s, want a directory outside of it", "none", root)
+	}
+}
@@ -0,0 +1,313 @@
+// Package server serves golinters reports over HTTP: the report
+// itself, a page per linter and a JSON API. The analysis can be
+// re-run in the background while the server keeps serving the
+// previous report.
+//
+// Routes:
+//
+//	GET  /                    the HTML report
+//	GET  /linters/{name}      a linter's detail page
+//	GET  /api/linters         the report as JSON (see golinters.Report)
+//	GET  /api/linters/{name}  a linter's result as JSON
+//	GET  /api/analysis        the state of the analysis (see Status)
+//	POST /api/analysis        start a re-analysis
+//
+// Since POST /api/analysis changes state, it only accepts requests
+// with a JSON body (Content-Type: application/json) that don't come
+// from another site, see checkPost.
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thomasheller/golinters"
+)
+
+// maxLog is the number of log lines kept in Status.
+const maxLog = 20
+
+// Status is the state of the (last) analysis.
+type Status struct {
+	Running bool `json:"running"`
+	// Stage is "fetch" or "analyze", see golinters.Options.
+	Stage  string `json:"stage,omitempty"`
+	Linter string `json:"linter,omitempty"`
+	// Done is the number of linters done in this stage, out of
+	// Total.
+	Done     int       `json:"done"`
+	Total    int       `json:"total"`
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+	// Error tells why the last analysis failed.
+	Error string `json:"error,omitempty"`
+	// Log holds the last log messages of the analysis.
+	Log []string `json:"log"`
+}
+
+// Server serves a report and re-runs the analysis on request.
+type Server struct {
+	// Template renders the report. If nil, the built-in template
+	// is used.
+	Template *template.Template
+	// OnReport, if not nil, is called with each new report, e.g.
+	// to record it in the history.
+	OnReport func(*golinters.Report)
+
+	ctx  context.Context
+	opts golinters.Options
+	mux  *http.ServeMux
+
+	mu     sync.Mutex
+	report *golinters.Report
+	status Status
+}
+
+// New returns a server for report, which may be nil if there is no
+// report yet. Re-analyses use opts, except for Logger and Progress,
+// and stop when ctx is done.
+func New(ctx context.Context, opts golinters.Options, report *golinters.Report) *Server {
+	if report == nil {
+		report = golinters.NewReport(nil)
+	}
+
+	s := &Server{
+		ctx:    ctx,
+		opts:   opts,
+		mux:    http.NewServeMux(),
+		report: report,
+		status: Status{Log: []string{}},
+	}
+
+	s.mux.HandleFunc("GET /{$}", s.index)
+	s.mux.HandleFunc("GET /linters/{name}", s.linter)
+	s.mux.HandleFunc("GET /api/linters", s.apiLinters)
+	s.mux.HandleFunc("GET /api/linters/{name}", s.apiLinter)
+	s.mux.HandleFunc("GET /api/analysis", s.apiStatus)
+	s.mux.HandleFunc("POST /api/analysis", s.apiAnalyze)
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Report returns the current report.
+func (s *Server) Report() *golinters.Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.report
+}
+
+// Status returns the state of the analysis.
+func (s *Server) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := s.status
+	st.Log = append([]string{}, st.Log...)
+	return st
+}
+
+// Analyze starts a re-analysis in the background, unless one is
+// running already. It reports whether a new analysis was started.
+func (s *Server) Analyze() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.status.Running {
+		return false
+	}
+
+	s.status = Status{Running: true, Started: time.Now(), Log: []string{}}
+
+	opts := s.opts
+	opts.Logger = log.New(logWriter{s}, "", 0)
+	opts.Progress = func(stage, linter string, done, total int) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.status.Stage, s.status.Linter = stage, linter
+		s.status.Done, s.status.Total = done, total
+	}
+
+	go s.analyze(opts)
+
+	return true
+}
+
+func (s *Server) analyze(opts golinters.Options) {
+	results, err := golinters.Analyze(s.ctx, opts)
+
+	var report *golinters.Report
+	if err == nil {
+		report = golinters.NewReport(results)
+	}
+
+	s.mu.Lock()
+	s.status.Running = false
+	s.status.Finished = time.Now()
+	if err != nil {
+		s.status.Error = err.Error()
+	} else {
+		s.report = report
+		s.status.Stage, s.status.Linter = "", ""
+		s.status.Done = s.status.Total
+	}
+	s.mu.Unlock()
+
+	if report != nil && s.OnReport != nil {
+		s.OnReport(report)
+	}
+}
+
+// logWriter appends log messages to the status.
+type logWriter struct {
+	s *Server
+}
+
+func (lw logWriter) Write(p []byte) (int, error) {
+	lw.s.mu.Lock()
+	defer lw.s.mu.Unlock()
+
+	st := &lw.s.status
+	st.Log = append(st.Log, strings.TrimRight(string(p), "\n"))
+	if len(st.Log) > maxLog {
+		st.Log = st.Log[len(st.Log)-maxLog:]
+	}
+
+	return len(p), nil
+}
+
+// index returns the index of the named linter's result, or -1.
+func index(results []golinters.Result, name string) int {
+	for i, r := range results {
+		if r.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func linterURL(name string) string {
+	return "/linters/" + url.PathEscape(name)
+}
+
+func (s *Server) index(w http.ResponseWriter, r *http.Request) {
+	tmpl := s.Template
+	if tmpl == nil {
+		tmpl = golinters.BuiltinTemplate()
+	}
+
+	report := s.Report()
+
+	data := golinters.NewTemplateData(report.Timestamp.Local(), report.Results)
+	data.LinterURL = linterURL
+	data.Server = true
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	buf.WriteTo(w)
+}
+
+func (s *Server) linter(w http.ResponseWriter, r *http.Request) {
+	results := s.Report().Results
+
+	i := index(results, r.PathValue("name"))
+	if i < 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := golinters.WriteLinterHTML(&buf, golinters.NewLinterData(results, i, "/", linterURL)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	buf.WriteTo(w)
+}
+
+func (s *Server) apiLinters(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.Report())
+}
+
+func (s *Server) apiLinter(w http.ResponseWriter, r *http.Request) {
+	results := s.Report().Results
+
+	i := index(results, r.PathValue("name"))
+	if i < 0 {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown linter"})
+		return
+	}
+	writeJSON(w, http.StatusOK, results[i])
+}
+
+func (s *Server) apiStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.Status())
+}
+
+// apiAnalyze starts a re-analysis. It responds with the status, and
+// 202 Accepted if the analysis was started or 409 Conflict if one was
+// running already.
+func (s *Server) apiAnalyze(w http.ResponseWriter, r *http.Request) {
+	if code, err := checkPost(r); err != nil {
+		writeJSON(w, code, map[string]string{"error": err.Error()})
+		return
+	}
+
+	code := http.StatusAccepted
+	if !s.Analyze() {
+		code = http.StatusConflict
+	}
+	writeJSON(w, code, s.Status())
+}
+
+// checkPost guards r against cross-site request forgery and returns
+// the status code to reject it with, if needed. Browsers identify
+// requests from other sites by Sec-Fetch-Site or, if older, Origin;
+// clients that send neither, like curl, are allowed. Requiring JSON
+// also rules out plain HTML forms, which any site can submit.
+func checkPost(r *http.Request) (int, error) {
+	if site := r.Header.Get("Sec-Fetch-Site"); site != "" {
+		if site != "same-origin" && site != "none" {
+			return http.StatusForbidden, errors.New("cross-site request rejected")
+		}
+	} else if origin := r.Header.Get("Origin"); origin != "" {
+		u, err := url.Parse(origin)
+		if err != nil || u.Host != r.Host {
+			return http.StatusForbidden, errors.New("cross-origin request rejected")
+		}
+	}
+
+	mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mt != "application/json" {
+		return http.StatusUnsupportedMediaType, errors.New("Content-Type must be application/json")
+	}
+
+	return 0, nil
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
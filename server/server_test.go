@@ -0,0 +1,43 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckPost(t *testing.T) {
+	tests := []struct {
+		name   string
+		header map[string]string
+		code   int
+	}{
+		{"curl", map[string]string{"Content-Type": "application/json"}, 0},
+		{"charset", map[string]string{"Content-Type": "application/json; charset=utf-8"}, 0},
+		{"same origin", map[string]string{"Content-Type": "application/json", "Sec-Fetch-Site": "same-origin", "Origin": "http://localhost:8080"}, 0},
+		{"address bar", map[string]string{"Content-Type": "application/json", "Sec-Fetch-Site": "none"}, 0},
+		{"old browser", map[string]string{"Content-Type": "application/json", "Origin": "http://localhost:8080"}, 0},
+
+		{"cross site", map[string]string{"Content-Type": "application/json", "Sec-Fetch-Site": "cross-site", "Origin": "http://localhost:8080"}, http.StatusForbidden},
+		{"same site", map[string]string{"Content-Type": "application/json", "Sec-Fetch-Site": "same-site"}, http.StatusForbidden},
+		{"old browser, other origin", map[string]string{"Content-Type": "application/json", "Origin": "https://evil.example"}, http.StatusForbidden},
+		{"old browser, other port", map[string]string{"Content-Type": "application/json", "Origin": "http://localhost:9090"}, http.StatusForbidden},
+		{"null origin", map[string]string{"Content-Type": "application/json", "Origin": "null"}, http.StatusForbidden},
+
+		{"no content type", map[string]string{}, http.StatusUnsupportedMediaType},
+		{"form", map[string]string{"Content-Type": "application/x-www-form-urlencoded", "Sec-Fetch-Site": "same-origin"}, http.StatusUnsupportedMediaType},
+		{"text", map[string]string{"Content-Type": "text/plain"}, http.StatusUnsupportedMediaType},
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest("POST", "http://localhost:8080/api/analysis", nil)
+		for k, v := range test.header {
+			r.Header.Set(k, v)
+		}
+
+		code, err := checkPost(r)
+		if code != test.code || (err != nil) != (test.code != 0) {
+			t.Errorf("%s: got %d, %v, want %d", test.name, code, err, test.code)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package golinters
+
+import (
+	"html/template"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// WriteSite writes a static site to dir: the report as index.html,
+// using the built-in template, and a page per linter in the linters
+// directory (see WriteLinterHTML). All links are relative, so the
+// site can be hosted anywhere.
+func WriteSite(dir string, results []Result) error {
+	return NewReport(results).WriteSite(dir)
+}
+
+// WriteSiteTemplate is like WriteSite, but renders index.html with
+// the given template.
+func WriteSiteTemplate(dir string, tmpl *template.Template, results []Result) error {
+	return NewReport(results).WriteSiteTemplate(dir, tmpl)
+}
+
+// WriteSite writes the report as a static site, see WriteSite.
+func (r *Report) WriteSite(dir string) error {
+	return r.WriteSiteTemplate(dir, builtinTemplate)
+}
+
+// WriteSiteTemplate is like Report.WriteSite, but renders index.html
+// with the given template.
+func (r *Report) WriteSiteTemplate(dir string, tmpl *template.Template) error {
+	if err := os.MkdirAll(filepath.Join(dir, "linters"), 0755); err != nil {
+		return err
+	}
+
+	results := r.Results
+
+	data := NewTemplateData(r.Timestamp.Local(), results)
+	data.LinterURL = func(name string) string { return "linters/" + url.PathEscape(pageFile(name)) }
+
+	if err := writeFile(filepath.Join(dir, "index.html"), func(f *os.File) error {
+		return tmpl.Execute(f, data)
+	}); err != nil {
+		return err
+	}
+
+	linterURL := func(name string) string { return url.PathEscape(pageFile(name)) }
+
+	for i, res := range results {
+		d := NewLinterData(results, i, "../index.html", linterURL)
+		if err := writeFile(filepath.Join(dir, "linters", pageFile(res.Name)), func(f *os.File) error {
+			return WriteLinterHTML(f, d)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pageFile returns the file name of a linter's page. Names are
+// escaped, so they can't contain path separators.
+func pageFile(name string) string {
+	return url.PathEscape(name) + ".html"
+}
+
+// writeFile creates file and writes to it with write.
+func writeFile(file string, write func(f *os.File) error) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,210 @@
+package golinters
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TemplateData is passed to HTML templates, both the built-in one and
+// those loaded with ParseHTMLTemplate.
+type TemplateData struct {
+	// Timestamp is Time formatted according to RFC 1123.
+	Timestamp string
+	// Time is when the report was generated.
+	Time time.Time
+	// Groups are the detector columns, grouped as in the header.
+	Groups []Group
+	// Results has one entry per linter, in registry order.
+	Results []Result
+	// Usages lists all possible usages from most to least direct,
+	// followed by Unknown, e.g. for a legend.
+	Usages []Usage
+	// LinterURL returns the URL of a linter's detail page, see
+	// WriteLinterHTML. It is nil if there are no such pages.
+	LinterURL func(name string) string
+	// Server is true if the report is served by "golinters serve",
+	// which can re-run the analysis.
+	Server bool
+}
+
+// NewTemplateData returns the template data of results analyzed at
+// time t.
+func NewTemplateData(t time.Time, results []Result) TemplateData {
+	return TemplateData{
+		Timestamp: t.Format(time.RFC1123),
+		Time:      t,
+		Groups:    columnGroups(results),
+		Results:   results,
+		Usages:    []Usage{UsedByMain, UsedByRepository, UsedByDependency, Unused, Unknown},
+	}
+}
+
+// TemplateFuncs returns the functions available to HTML templates:
+//
+//	cell DETECTION
+//		renders a detection as a table cell, colored by usage, with
+//		its evidence as title and a link to the source if known. The
+//		cell's data-group and data-value attributes hold the column
+//		group and the usage as a number (greater is more direct).
+//	used DETECTION
+//		reports whether the capability is used at all.
+//	known DETECTION
+//		reports whether the usage is known.
+//	describe USAGE
+//		returns a sentence describing the usage, e.g. for a legend.
+//	column RESULT NAME
+//		returns the named detection of a result.
+//	group RESULT GROUP
+//		returns the detections of a result that belong to a group.
+//	sortBy KEY RESULTS
+//		returns the results sorted by "name", "maintainer" or the
+//		name of a detector (most direct usage first).
+//	reverse RESULTS
+//		returns the results in reverse order.
+//	filter NAME RESULTS
+//		returns the results that use the named detector's capability.
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"cell":     cell,
+		"used":     func(d Detection) bool { return d.Usage > Unused },
+		"known":    func(d Detection) bool { return d.Usage != Unknown },
+		"describe": describe,
+		"column":   column,
+		"group":    group,
+		"sortBy":   sortBy,
+		"reverse":  reverse,
+		"filter":   filter,
+	}
+}
+
+// ParseHTMLTemplate loads a custom HTML template from file. If
+// partials is not empty, all *.html and *.tmpl files in that
+// directory are loaded too, so the template can use them by their
+// file names, e.g. {{ template "row.html" . }}.
+func ParseHTMLTemplate(file, partials string) (*template.Template, error) {
+	tmpl := template.New(filepath.Base(file)).Funcs(TemplateFuncs())
+
+	tmpl, err := tmpl.ParseFiles(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if partials != "" {
+		for _, pattern := range []string{"*.html", "*.tmpl"} {
+			files, err := filepath.Glob(filepath.Join(partials, pattern))
+			if err != nil {
+				return nil, err
+			}
+			if len(files) == 0 {
+				continue
+			}
+			if tmpl, err = tmpl.ParseFiles(files...); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return tmpl, nil
+}
+
+// WriteHTMLTemplate renders results with the given template. The
+// template is executed with TemplateData.
+func WriteHTMLTemplate(w io.Writer, tmpl *template.Template, results []Result) error {
+	return NewReport(results).WriteHTMLTemplate(w, tmpl)
+}
+
+// WriteHTMLTemplate renders the report with the given template,
+// labeled with the time it was written.
+func (r *Report) WriteHTMLTemplate(w io.Writer, tmpl *template.Template) error {
+	return tmpl.Execute(w, NewTemplateData(r.Timestamp.Local(), r.Results))
+}
+
+func cell(d Detection) template.HTML {
+	title := template.HTMLEscapeString(d.Title())
+	text := template.HTMLEscapeString(d.Usage.String())
+
+	if u := d.URL(); u != "" {
+		text = fmt.Sprintf(`<a href="%s">%s</a>`, template.HTMLEscapeString(u), text)
+	}
+
+	return template.HTML(fmt.Sprintf(`<td class="%s" title="%s" data-group="%s" data-value="%d">%s</td>`,
+		d.Usage.Class(), title, template.HTMLEscapeString(d.Group), int(d.Usage), text))
+}
+
+func describe(u Usage) string {
+	switch u {
+	case UsedByMain:
+		return "used by the linter's main package"
+	case UsedByRepository:
+		return "used by another package in the linter's repository"
+	case UsedByDependency:
+		return "only used by third-party dependencies"
+	case Unknown:
+		return "unknown, because the linter couldn't be analyzed"
+	}
+	return "not used"
+}
+
+func column(r Result, name string) Detection {
+	d, _ := r.Column(name)
+	return d
+}
+
+func group(r Result, name string) []Detection {
+	var ds []Detection
+	for _, d := range r.Columns {
+		if d.Group == name {
+			ds = append(ds, d)
+		}
+	}
+	return ds
+}
+
+func sortBy(key string, results []Result) []Result {
+	sorted := append([]Result{}, results...)
+
+	var less func(a, b Result) bool
+
+	switch key {
+	case "name":
+		less = func(a, b Result) bool { return strings.ToLower(a.Name) < strings.ToLower(b.Name) }
+	case "maintainer":
+		less = func(a, b Result) bool { return strings.ToLower(maintainer(a)) < strings.ToLower(maintainer(b)) }
+	default:
+		less = func(a, b Result) bool { return column(a, key).Usage > column(b, key).Usage }
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+
+	return sorted
+}
+
+func maintainer(r Result) string {
+	if r.Repo == nil {
+		return ""
+	}
+	return r.Repo.Maintainer
+}
+
+func reverse(results []Result) []Result {
+	reversed := make([]Result, len(results))
+	for i, r := range results {
+		reversed[len(results)-1-i] = r
+	}
+	return reversed
+}
+
+func filter(name string, results []Result) []Result {
+	var filtered []Result
+	for _, r := range results {
+		if column(r, name).Usage > Unused {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
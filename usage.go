@@ -0,0 +1,166 @@
+package golinters
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Usage describes how directly a linter uses a package. Greater
+// values mean closer to the linter itself.
+type Usage int
+
+const (
+	// Unknown means the linter couldn't be checked.
+	Unknown Usage = iota - 1
+	// Unused means the package isn't imported at all.
+	Unused
+	// UsedByDependency means the package is only imported by
+	// third-party dependencies of the linter.
+	UsedByDependency
+	// UsedByRepository means the package is imported by a package
+	// in the linter's own repository, but not by its main package.
+	UsedByRepository
+	// UsedByMain means the linter's main package imports the
+	// package directly.
+	UsedByMain
+)
+
+// String returns the text shown in the report.
+func (u Usage) String() string {
+	switch u {
+	case UsedByMain:
+		return "Y"
+	case UsedByRepository:
+		return "repo"
+	case UsedByDependency:
+		return "dep"
+	case Unknown:
+		return "?"
+	}
+	return "N"
+}
+
+// Class returns the CSS class used in the HTML report.
+func (u Usage) Class() string {
+	switch u {
+	case UsedByMain:
+		return "t"
+	case UsedByRepository:
+		return "r"
+	case UsedByDependency:
+		return "d"
+	case Unknown:
+		return "u"
+	}
+	return "f"
+}
+
+var usageNames = map[Usage]string{
+	Unknown:          "unknown",
+	Unused:           "unused",
+	UsedByDependency: "dependency",
+	UsedByRepository: "repository",
+	UsedByMain:       "main",
+}
+
+// MarshalText encodes u as one of "unknown", "unused", "dependency",
+// "repository" or "main".
+func (u Usage) MarshalText() ([]byte, error) {
+	name, ok := usageNames[u]
+	if !ok {
+		return nil, fmt.Errorf("invalid usage %d", int(u))
+	}
+	return []byte(name), nil
+}
+
+// UnmarshalText decodes a usage encoded by MarshalText.
+func (u *Usage) UnmarshalText(text []byte) error {
+	for v, name := range usageNames {
+		if string(text) == name {
+			*u = v
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid usage %q", text)
+}
+
+// add records another use, keeping the most direct one. Any known
+// use overrides Unknown.
+func (u *Usage) add(v Usage) {
+	if v > *u {
+		*u = v
+	}
+}
+
+// Evidence tells where a capability was found.
+type Evidence struct {
+	// Package is the import path of the package that imports
+	// (or calls) the capability.
+	Package string `json:"package"`
+	// Module is the path of the module the file belongs to.
+	Module string `json:"module,omitempty"`
+	// File is the file's path relative to the module root.
+	File string `json:"file,omitempty"`
+	// Line is the line within the file, or 0 if unknown.
+	Line int `json:"line,omitempty"`
+	// Text optionally describes the evidence, e.g. a matching
+	// definition.
+	Text string `json:"text,omitempty"`
+	// URL links to the evidence, if it is in the linter's own
+	// repository.
+	URL string `json:"url,omitempty"`
+}
+
+// String returns a short description like "pkg (file:line)".
+func (e Evidence) String() string {
+	s := e.Package
+	if e.File != "" {
+		if e.Line > 0 {
+			s += fmt.Sprintf(" (%s:%d)", e.File, e.Line)
+		} else {
+			s += fmt.Sprintf(" (%s)", e.File)
+		}
+	}
+	if e.Text != "" {
+		s += ": " + e.Text
+	}
+	return s
+}
+
+// Detection is the outcome of a detector for a linter.
+type Detection struct {
+	// Name and Group identify the detector. They are filled in
+	// by Analyze.
+	Name  string `json:"name"`
+	Group string `json:"group"`
+	Usage Usage  `json:"usage"`
+	// Evidence lists why the detector decided on Usage. It is
+	// empty if the capability is unused.
+	Evidence []Evidence `json:"evidence,omitempty"`
+	// Reason tells why Usage is Unknown.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Title lists all evidence, one per line, or the reason why the
+// usage is unknown.
+func (d Detection) Title() string {
+	if d.Usage == Unknown {
+		return d.Reason
+	}
+
+	var lines []string
+	for _, e := range d.Evidence {
+		lines = append(lines, e.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// URL returns the link to the first evidence that has one.
+func (d Detection) URL() string {
+	for _, e := range d.Evidence {
+		if e.URL != "" {
+			return e.URL
+		}
+	}
+	return ""
+}